@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/goblimey/scaffolder/scaffold"
+)
+
+// checkCommand runs the "check" command:
+//
+//	scaffolder check [spec file]
+//
+// It checks the spec file (by default scaffold.json, scaffold.yaml or
+// scaffold.toml) and reports any problems, without generating anything.  It
+// exits with status 1 if there are any errors, so it can be used in a CI
+// pipeline.
+func checkCommand(args []string) {
+	log.SetPrefix("check ")
+
+	specFile := scaffold.DefaultSpecFile(scaffold.OSFS)
+	if len(args) > 1 {
+		log.Println("usage: scaffolder check [spec file]")
+		os.Exit(2)
+	}
+	if len(args) == 1 {
+		specFile = args[0]
+	}
+
+	_, problems, err := scaffold.LoadSpec(scaffold.OSFS, specFile)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	for _, problem := range problems {
+		fmt.Println(problem.String())
+	}
+	errorCount := problems.Errors()
+	if errorCount > 0 {
+		fmt.Printf("%s: %d error(s), %d warning(s)\n", specFile, errorCount,
+			len(problems)-errorCount)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: OK, %d warning(s)\n", specFile, len(problems))
+}
@@ -0,0 +1,8 @@
+module github.com/goblimey/scaffolder
+
+go 1.21
+
+require (
+	github.com/BurntSushi/toml v1.5.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goblimey/scaffolder/scaffold"
+)
+
+// The import commands write a spec describing something that already exists,
+// such as a database schema, as a starting point.  The spec only has the
+// settings that the scaffolder can work out, and placeholders for the rest,
+// so the user is expected to edit it before generating anything.
+
+// importedSpec, importedResource and importedField are the parts of Spec,
+// Resource and Field that an import command writes, in the order that a
+// person would write them.
+type importedSpec struct {
+	Name       string             `json:"name"`
+	SourceBase string             `json:"sourcebase"`
+	DB         string             `json:"db"`
+	DBUser     string             `json:"dbuser"`
+	DBPassword string             `json:"dbpassword"`
+	DBServer   string             `json:"dbserver"`
+	ORM        string             `json:"orm"`
+	Resources  []importedResource `json:"resources"`
+}
+
+type importedResource struct {
+	Name       string          `json:"name"`
+	PluralName string          `json:"plural,omitempty"`
+	TableName  string          `json:"tableName,omitempty"`
+	Fields     []importedField `json:"fields"`
+}
+
+type importedField struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Mandatory bool   `json:"mandatory,omitempty"`
+	MaxLength int    `json:"maxLength,omitempty"`
+	Column    string `json:"column,omitempty"`
+}
+
+// newImportedSpec returns an imported spec for the project with the given
+// name, with placeholders for the settings that can't be worked out.
+func newImportedSpec(name string) importedSpec {
+	name = scaffold.CamelCase(name)
+	if name == "" {
+		name = "project"
+	}
+	return importedSpec{
+		Name:       name,
+		SourceBase: "github.com/yourname/" + strings.ToLower(name),
+		DB:         "mysql",
+		DBUser:     "webuser",
+		DBPassword: "secret",
+		DBServer:   "localhost",
+		ORM:        "gorp",
+		Resources:  make([]importedResource, 0),
+	}
+}
+
+// resourceForTable returns a resource for a database table.  The resource
+// name is the singular of the table name in camel case.  The plural is only
+// given if the scaffolder would get it wrong, and the table name only if it's
+// different from the plural.
+func resourceForTable(tableName string) importedResource {
+	var resource importedResource
+	camel := scaffold.CamelCase(tableName)
+	resource.Name = scaffold.Singular(camel)
+	pluralName := scaffold.Plural(resource.Name)
+	if camel != pluralName {
+		resource.PluralName = camel
+		pluralName = camel
+	}
+	if tableName != pluralName {
+		resource.TableName = tableName
+	}
+	resource.Fields = make([]importedField, 0)
+	return resource
+}
+
+// writeImportedSpec writes an imported spec to the named file, which is only
+// replaced in overwrite mode, and reports any problems with it.  The notes
+// are things that the user should know about the import.
+func writeImportedSpec(specFile string, spec importedSpec, notes []string) {
+	data, err := json.MarshalIndent(spec, "", "    ")
+	if err != nil {
+		log.Printf("cannot convert the spec to JSON - %s", err.Error())
+		os.Exit(-1)
+	}
+	data = append(data, '\n')
+
+	if strings.ToLower(filepath.Ext(specFile)) != ".json" {
+		log.Printf("%s - the spec is written in JSON, so the file name must end with .json",
+			specFile)
+		os.Exit(-1)
+	}
+	if fileExists(specFile) && !overwriteMode {
+		log.Printf("%s already exists - use -overwrite to replace it", specFile)
+		os.Exit(-1)
+	}
+	err = ioutil.WriteFile(specFile, data, 0644)
+	if err != nil {
+		log.Printf("cannot write %s - %s", specFile, err.Error())
+		os.Exit(-1)
+	}
+	fmt.Printf("wrote %s with %d resource(s)\n", specFile, len(spec.Resources))
+
+	for _, note := range notes {
+		fmt.Println("note: " + note)
+	}
+
+	// Check the result in the usual way.  A name that is fine in the
+	// original may not be allowed in the spec.
+	_, problems, err := scaffold.LoadSpec(scaffold.OSFS, specFile)
+	if err != nil {
+		log.Println(err.Error())
+		os.Exit(-1)
+	}
+	for _, problem := range problems {
+		fmt.Println(problem.String())
+	}
+	fmt.Printf("edit the sourcebase and the database settings in %s before running the scaffolder\n",
+		specFile)
+}
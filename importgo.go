@@ -0,0 +1,406 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goblimey/scaffolder/scaffold"
+)
+
+// The import-go command reads the exported struct types in a Go package and
+// writes a spec with a resource for each struct and a field for each of its
+// exported fields:
+//
+//	scaffolder import-go ./path [spec file]
+//
+// The path is a package directory or a single Go file, and the spec file is
+// scaffold.json by default.  The struct CustomerOrder becomes the resource
+// customerOrder.  A field is named after its db tag if it has one, otherwise
+// after its json tag, otherwise after the Go field, and a field tagged "-" in
+// either is left out, as is the ID.  A db tag that isn't in the scaffolder's
+// style, such as "created_at", is kept as the name of the column.  The Go types are mapped to the nearest
+// field type (int32 becomes an int, sql.NullString becomes a string) and a
+// field whose validate or binding tag says "required" becomes mandatory.  The
+// fields of an embedded struct from the same package are added in its place,
+// and a struct that is embedded in another, such as a Base struct holding the
+// common fields, is assumed to be a mixin and doesn't become a resource of
+// its own.  The package is only parsed, not compiled, so it doesn't need to build.
+
+// goFieldTypes maps the Go types that the scaffolder supports to field types.
+var goFieldTypes = map[string]string{
+	"string":          "string",
+	"int":             "int",
+	"int8":            "int",
+	"int16":           "int",
+	"int32":           "int",
+	"int64":           "int",
+	"rune":            "int",
+	"uint":            "uint",
+	"uint8":           "uint",
+	"uint16":          "uint",
+	"uint32":          "uint",
+	"uint64":          "uint",
+	"byte":            "uint",
+	"float32":         "float",
+	"float64":         "float",
+	"bool":            "bool",
+	"sql.NullString":  "string",
+	"sql.NullInt16":   "int",
+	"sql.NullInt32":   "int",
+	"sql.NullInt64":   "int",
+	"sql.NullByte":    "uint",
+	"sql.NullFloat64": "float",
+	"sql.NullBool":    "bool",
+}
+
+// goPackage holds the parsed source of a package.
+type goPackage struct {
+	fileSet *token.FileSet
+	name    string
+	files   []*ast.File
+	types   map[string]*ast.TypeSpec // every type declared in the package
+}
+
+// importGoCommand runs the "import-go" command.
+func importGoCommand(args []string) {
+	log.SetPrefix("import-go ")
+
+	if len(args) < 1 || len(args) > 2 {
+		log.Println("usage: scaffolder [-overwrite] import-go <package directory or file> [spec file]")
+		os.Exit(-1)
+	}
+	sourcePath := args[0]
+	specFile := "scaffold.json"
+	if len(args) == 2 {
+		specFile = args[1]
+	}
+
+	pkg, err := parseGoPackage(sourcePath)
+	if err != nil {
+		log.Println(err.Error())
+		os.Exit(-1)
+	}
+
+	spec, notes, err := importGo(sourcePath, pkg)
+	if err != nil {
+		log.Println(err.Error())
+		os.Exit(-1)
+	}
+	writeImportedSpec(specFile, spec, notes)
+}
+
+// parseGoPackage parses the Go files in a directory, leaving out the tests,
+// or a single Go file.
+func parseGoPackage(sourcePath string) (*goPackage, error) {
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find %s - %s", sourcePath, err.Error())
+	}
+	fileNames := []string{sourcePath}
+	if info.IsDir() {
+		fileNames, err = filepath.Glob(filepath.Join(sourcePath, "*.go"))
+		if err != nil {
+			return nil, fmt.Errorf("cannot list the Go files in %s - %s", sourcePath, err.Error())
+		}
+		sort.Strings(fileNames)
+	}
+
+	pkg := goPackage{fileSet: token.NewFileSet(), types: make(map[string]*ast.TypeSpec)}
+	for _, fileName := range fileNames {
+		if strings.HasSuffix(fileName, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(pkg.fileSet, fileName, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.name == "" {
+			pkg.name = file.Name.Name
+		} else if file.Name.Name != pkg.name {
+			return nil, fmt.Errorf("%s contains more than one package (%s and %s)",
+				sourcePath, pkg.name, file.Name.Name)
+		}
+		pkg.files = append(pkg.files, file)
+		for _, declaration := range file.Decls {
+			if general, ok := declaration.(*ast.GenDecl); ok && general.Tok == token.TYPE {
+				for _, spec := range general.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					pkg.types[typeSpec.Name.Name] = typeSpec
+				}
+			}
+		}
+	}
+	if len(pkg.files) == 0 {
+		return nil, fmt.Errorf("there are no Go files in %s", sourcePath)
+	}
+	return &pkg, nil
+}
+
+// importGo builds a spec from the exported struct types of a package.  The
+// project is named after the package, or after its directory if it's the
+// main package.  It returns the spec and notes about anything that wasn't
+// imported exactly.
+func importGo(sourcePath string, pkg *goPackage) (importedSpec, []string, error) {
+	// A struct that's embedded in others gives the same notes each time, so
+	// each note is only kept once.
+	notes := make([]string, 0)
+	noted := make(map[string]bool)
+	note := func(pos token.Pos, format string, args ...interface{}) {
+		text := pkg.fileSet.Position(pos).String() + ": " + fmt.Sprintf(format, args...)
+		if !noted[text] {
+			noted[text] = true
+			notes = append(notes, text)
+		}
+	}
+
+	embedders := pkg.embedders()
+	resources := make([]importedResource, 0)
+	for _, file := range pkg.files {
+		for _, declaration := range file.Decls {
+			general, ok := declaration.(*ast.GenDecl)
+			if !ok || general.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range general.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok || !typeSpec.Name.IsExported() {
+					continue
+				}
+				if embedder, ok := embedders[typeSpec.Name.Name]; ok {
+					note(typeSpec.Pos(), "%s is embedded in %s, so it's not a resource of its own",
+						typeSpec.Name.Name, embedder)
+					continue
+				}
+				resource := importedResource{Name: scaffold.CamelCase(typeSpec.Name.Name)}
+				resource.Fields = pkg.structFields(typeSpec.Name.Name, structType,
+					make(map[string]bool), note)
+				if len(resource.Fields) == 0 {
+					note(typeSpec.Pos(), "%s has no fields that can be imported, so it's left out",
+						typeSpec.Name.Name)
+					continue
+				}
+				resources = append(resources, resource)
+			}
+		}
+	}
+
+	if len(resources) == 0 {
+		return importedSpec{}, nil,
+			fmt.Errorf("%s: there are no exported struct types with fields that can be imported",
+				sourcePath)
+	}
+	projectName := pkg.name
+	if projectName == "main" {
+		if absolute, err := filepath.Abs(sourcePath); err == nil {
+			if !strings.HasSuffix(absolute, ".go") {
+				projectName = filepath.Base(absolute)
+			} else {
+				projectName = filepath.Base(filepath.Dir(absolute))
+			}
+		}
+	}
+	spec := newImportedSpec(projectName)
+	spec.Resources = resources
+	return spec, notes, nil
+}
+
+// embedders finds the structs in the package that are embedded in other
+// structs.  It returns a map from the name of each embedded struct to the
+// name of the first struct that embeds it.
+func (pkg *goPackage) embedders() map[string]string {
+	embedders := make(map[string]string)
+	for _, file := range pkg.files {
+		for _, declaration := range file.Decls {
+			general, ok := declaration.(*ast.GenDecl)
+			if !ok || general.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range general.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, goField := range structType.Fields.List {
+					if len(goField.Names) > 0 {
+						continue
+					}
+					embeddedName := strings.TrimPrefix(goTypeName(goField.Type), "*")
+					if embeddedName == typeSpec.Name.Name {
+						continue
+					}
+					if embedded, ok := pkg.types[embeddedName]; ok {
+						_, isStruct := embedded.Type.(*ast.StructType)
+						if _, found := embedders[embeddedName]; isStruct && !found {
+							embedders[embeddedName] = typeSpec.Name.Name
+						}
+					}
+				}
+			}
+		}
+	}
+	return embedders
+}
+
+// structFields returns the fields for the exported fields of a struct.
+// The embedded structs that have been expanded so far are marked as seen,
+// so that a struct that embeds itself doesn't go on for ever.
+func (pkg *goPackage) structFields(structName string, structType *ast.StructType,
+	seen map[string]bool, note func(token.Pos, string, ...interface{})) []importedField {
+
+	fields := make([]importedField, 0)
+	for _, goField := range structType.Fields.List {
+		tag := reflect.StructTag("")
+		if goField.Tag != nil {
+			if value, err := strconv.Unquote(goField.Tag.Value); err == nil {
+				tag = reflect.StructTag(value)
+			}
+		}
+		dbName := tagName(tag.Get("db"))
+		jsonName := tagName(tag.Get("json"))
+
+		if len(goField.Names) == 0 {
+			// An embedded field.
+			typeName := goTypeName(goField.Type)
+			if dbName == "-" || jsonName == "-" {
+				continue
+			}
+			embeddedName := strings.TrimPrefix(typeName, "*")
+			if embedded, ok := pkg.types[embeddedName]; ok && !seen[embeddedName] {
+				if embeddedStruct, ok := embedded.Type.(*ast.StructType); ok {
+					seen[embeddedName] = true
+					fields = append(fields,
+						pkg.structFields(embeddedName, embeddedStruct, seen, note)...)
+					delete(seen, embeddedName)
+					continue
+				}
+			}
+			note(goField.Pos(), "%s embeds %s, which is left out", structName, typeName)
+			continue
+		}
+
+		for _, goName := range goField.Names {
+			if !goName.IsExported() {
+				continue
+			}
+			if dbName == "-" || jsonName == "-" {
+				note(goName.Pos(), "%s.%s is tagged \"-\", so it's left out",
+					structName, goName.Name)
+				continue
+			}
+			name := goName.Name
+			switch {
+			case dbName != "":
+				name = dbName
+			case jsonName != "":
+				name = jsonName
+			}
+			if strings.EqualFold(name, "id") {
+				// The generated code adds its own id.
+				continue
+			}
+
+			field := importedField{Name: scaffold.CamelCase(name),
+				Mandatory: tagHas(tag.Get("validate"), "required") ||
+					tagHas(tag.Get("binding"), "required")}
+			typeName := goTypeName(goField.Type)
+			var mapping string
+			field.Type, mapping = pkg.fieldType(goField.Type, 0)
+			switch {
+			case field.Type == "":
+				note(goName.Pos(), "%s.%s is %s, which can't be imported, so it's left out",
+					structName, goName.Name, typeName)
+				continue
+			case mapping != "":
+				note(goName.Pos(), "%s.%s is %s, which is imported as %s",
+					structName, goName.Name, mapping, field.Type)
+			}
+			if dbName != "" && !strings.EqualFold(field.Name, dbName) {
+				field.Column = dbName
+				note(goName.Pos(), "%s.%s becomes the field %s, with \"column\": %q",
+					structName, goName.Name, field.Name, dbName)
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// fieldType returns the field type for a Go type, or "" if there isn't one.
+// A type declared in the package is replaced by the type that it's based
+// on, and a pointer by the type that it points to.  If the mapping is only
+// approximate, it also returns the name of the Go type, for a note.
+func (pkg *goPackage) fieldType(goType ast.Expr, depth int) (string, string) {
+	typeName := goTypeName(goType)
+	if fieldType, ok := goFieldTypes[typeName]; ok {
+		return fieldType, ""
+	}
+	switch t := goType.(type) {
+	case *ast.StarExpr:
+		return pkg.fieldType(t.X, depth+1)
+	case *ast.Ident:
+		if typeSpec, ok := pkg.types[t.Name]; ok && depth < 10 {
+			return pkg.fieldType(typeSpec.Type, depth+1)
+		}
+	case *ast.SelectorExpr:
+		if typeName == "time.Time" || typeName == "sql.NullTime" {
+			return "string", typeName
+		}
+	}
+	return "", ""
+}
+
+// goTypeName returns the source text of a simple Go type, such as "string",
+// "*time.Time" or "[]byte".
+func goTypeName(goType ast.Expr) string {
+	switch t := goType.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return goTypeName(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + goTypeName(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + goTypeName(t.Elt)
+		}
+		return "[...]" + goTypeName(t.Elt)
+	case *ast.MapType:
+		return "map[" + goTypeName(t.Key) + "]" + goTypeName(t.Value)
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	case *ast.FuncType:
+		return "func"
+	case *ast.ChanType:
+		return "chan"
+	}
+	return "an unsupported type"
+}
+
+// tagName returns the name part of a struct tag value such as "name,omitempty".
+func tagName(value string) string {
+	return strings.TrimSpace(strings.Split(value, ",")[0])
+}
+
+// tagHas returns true if a struct tag value such as "required,max=40" has the
+// given option.
+func tagHas(value string, option string) bool {
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parseTestPackage writes the source to a file in a new directory and parses
+// the directory.
+func parseTestPackage(t *testing.T, source string) (string, *goPackage) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "shop")
+	err := os.Mkdir(dir, 0777)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "domain.go"), []byte(source), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := parseGoPackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, pkg
+}
+
+func TestImportGo(t *testing.T) {
+	const source = `package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Status string
+
+// Base holds the fields that every record has.
+type Base struct {
+	ID      uint64    ` + "`db:\"id\"`" + `
+	Created time.Time ` + "`db:\"created_at\"`" + `
+}
+
+type CustomerOrder struct {
+	Base
+	Reference string         ` + "`json:\"ref\" validate:\"required,max=20\"`" + `
+	Quantity  int32          ` + "`db:\"quantity\" binding:\"required\"`" + `
+	Price     float64
+	Paid      *bool
+	Status    Status
+	Notes     sql.NullString
+	Secret    string ` + "`json:\"-\"`" + `
+	Lines     []string
+	internal  int
+}
+
+type empty struct {
+	Name string
+}
+
+type Customer struct {
+	*Base
+	Name string ` + "`validate:\"required\"`" + `
+}
+
+type Nothing struct {
+	Lines []string
+}
+`
+	dir, pkg := parseTestPackage(t, source)
+	spec, notes, err := importGo(dir, pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Name != "domain" {
+		t.Errorf("want the project domain, got %s", spec.Name)
+	}
+
+	want := []importedResource{
+		{Name: "customerOrder", Fields: []importedField{
+			{Name: "createdAt", Type: "string", Column: "created_at"},
+			{Name: "ref", Type: "string", Mandatory: true},
+			{Name: "quantity", Type: "int", Mandatory: true},
+			{Name: "price", Type: "float"},
+			{Name: "paid", Type: "bool"},
+			{Name: "status", Type: "string"},
+			{Name: "notes", Type: "string"},
+		}},
+		{Name: "customer", Fields: []importedField{
+			{Name: "createdAt", Type: "string", Column: "created_at"},
+			{Name: "name", Type: "string", Mandatory: true},
+		}},
+	}
+	if !reflect.DeepEqual(spec.Resources, want) {
+		t.Errorf("want resources\n%+v\ngot\n%+v", want, spec.Resources)
+	}
+
+	wantNotes := []string{
+		"Base is embedded in CustomerOrder, so it's not a resource of its own",
+		"Base.Created is time.Time, which is imported as string",
+		`Base.Created becomes the field createdAt, with "column": "created_at"`,
+		"CustomerOrder.Secret is tagged \"-\", so it's left out",
+		"CustomerOrder.Lines is []string, which can't be imported, so it's left out",
+		"Nothing has no fields that can be imported, so it's left out",
+	}
+	for _, wantNote := range wantNotes {
+		count := 0
+		for _, note := range notes {
+			if strings.HasSuffix(note, ": "+wantNote) {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("want the note %q once, got it %d times in\n%s", wantNote, count,
+				strings.Join(notes, "\n"))
+		}
+	}
+}
+
+func TestImportGoEmbedders(t *testing.T) {
+	const source = `package domain
+
+type Base struct {
+	Name string
+}
+
+type Audit struct {
+	Base
+	By string
+}
+
+type Cat struct {
+	Audit
+	Age int
+}
+
+type Loop struct {
+	*Loop
+	Name string
+}
+
+type Alias Base
+`
+	_, pkg := parseTestPackage(t, source)
+	want := map[string]string{"Base": "Audit", "Audit": "Cat"}
+	if got := pkg.embedders(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestImportGoErrors(t *testing.T) {
+	var testData = []struct {
+		description string
+		source      string
+		want        string
+	}{
+		{"no structs", "package domain\n\ntype Status string\n",
+			"there are no exported struct types with fields that can be imported"},
+		{"only unexported", "package domain\n\ntype cat struct {\n\tName string\n}\n",
+			"there are no exported struct types with fields that can be imported"},
+	}
+
+	for _, td := range testData {
+		dir, pkg := parseTestPackage(t, td.source)
+		_, _, err := importGo(dir, pkg)
+		if err == nil {
+			t.Errorf("%s: want an error", td.description)
+			continue
+		}
+		if !strings.HasSuffix(err.Error(), td.want) {
+			t.Errorf("%s: want %q, got %q", td.description, td.want, err.Error())
+		}
+	}
+}
+
+func TestTagNameAndTagHas(t *testing.T) {
+	if got := tagName(" first_name ,omitempty"); got != "first_name" {
+		t.Errorf("tagName: want first_name, got %q", got)
+	}
+	if !tagHas("max=40, required", "required") {
+		t.Error("tagHas: want true for required")
+	}
+	if tagHas("requiredIf=x", "required") {
+		t.Error("tagHas: want false for requiredIf")
+	}
+}
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goblimey/scaffolder/scaffold"
+)
+
+// The import-sql command reads the CREATE TABLE statements in a MySQL or
+// Postgres schema and writes a spec with a resource for each table and a
+// field for each column:
+//
+//	scaffolder import-sql schema.sql [spec file]
+//
+// The spec file is scaffold.json by default.  The column types are mapped to
+// the nearest field type (varchar(40) becomes a string with a maximum length
+// of 40), a NOT NULL column becomes a mandatory field and the names are
+// converted to the scaffolder's style (the table "customer_orders" becomes
+// the resource "customerOrder" with the table name "customer_orders").  The
+// generated code adds its own id column, so a column called id is left out.
+// Everything else in the schema is ignored.
+//
+// A column such as "first_name" becomes the field firstName, and the field
+// keeps the name of the column ("column": "first_name"), so the generated code
+// works with the existing table.  Each renamed column is listed in the notes.
+
+// An sqlToken is a word, a number, a quoted name or string, or a punctuation
+// character in the schema.
+type sqlToken struct {
+	text   string
+	quoted bool // a quoted name such as `order` or "order", or a string
+	line   int
+}
+
+// is returns true if the token is the given (lower case) keyword.
+func (t sqlToken) is(keyword string) bool {
+	return !t.quoted && strings.ToLower(t.text) == keyword
+}
+
+// sqlColumn is a column of a table.
+type sqlColumn struct {
+	name       string
+	typeName   string // in lower case, for example "varchar"
+	arguments  []string
+	unsigned   bool
+	array      bool
+	notNull    bool
+	primaryKey bool
+	line       int
+}
+
+// importSQLCommand runs the "import-sql" command.
+func importSQLCommand(args []string) {
+	log.SetPrefix("import-sql ")
+
+	if len(args) < 1 || len(args) > 2 {
+		log.Println("usage: scaffolder [-overwrite] import-sql <schema file> [spec file]")
+		os.Exit(-1)
+	}
+	schemaFile := args[0]
+	specFile := "scaffold.json"
+	if len(args) == 2 {
+		specFile = args[1]
+	}
+
+	data, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		log.Printf("cannot read %s - %s", schemaFile, err.Error())
+		os.Exit(-1)
+	}
+
+	spec, notes, err := importSQL(schemaFile, string(data))
+	if err != nil {
+		log.Println(err.Error())
+		os.Exit(-1)
+	}
+	writeImportedSpec(specFile, spec, notes)
+}
+
+// importSQL builds a spec from the text of a schema.  The project is named
+// after the database if the schema creates or uses one, otherwise after the
+// schema file.  It returns the spec and notes about anything that wasn't
+// imported exactly.
+func importSQL(schemaFile string, text string) (importedSpec, []string, error) {
+	notes := make([]string, 0)
+	note := func(line int, format string, args ...interface{}) {
+		notes = append(notes, fmt.Sprintf("%s:%d: ", schemaFile, line)+
+			fmt.Sprintf(format, args...))
+	}
+
+	tokens, err := sqlTokens(text)
+	if err != nil {
+		return importedSpec{}, nil, fmt.Errorf("%s:%s", schemaFile, err.Error())
+	}
+
+	projectName := strings.TrimSuffix(filepath.Base(schemaFile), filepath.Ext(schemaFile))
+	resources := make([]importedResource, 0)
+	for _, statement := range sqlStatements(tokens) {
+		switch {
+		case len(statement) >= 3 && statement[0].is("create") &&
+			(statement[1].is("database") || statement[1].is("schema")):
+			projectName = sqlObjectName(statement[2:])
+		case len(statement) >= 2 && statement[0].is("use"):
+			projectName = statement[1].text
+		case statement[0].is("create"):
+			tableName, definitions, err := sqlCreateTable(statement)
+			if err != nil {
+				return importedSpec{}, nil, fmt.Errorf("%s:%s", schemaFile, err.Error())
+			}
+			if tableName == "" {
+				continue
+			}
+			resource := resourceForTable(tableName)
+			primaryKey := make([]string, 0)
+			for _, definition := range definitions {
+				if isTableConstraint(definition) {
+					if definition[0].is("primary") || len(definition) > 2 &&
+						definition[0].is("constraint") && definition[2].is("primary") {
+						primaryKey = append(primaryKey, sqlColumnList(definition)...)
+					}
+					continue
+				}
+				column := sqlColumnDefinition(definition)
+				if column.primaryKey {
+					primaryKey = append(primaryKey, column.name)
+				}
+				if strings.EqualFold(column.name, "id") {
+					// The generated code adds its own id.
+					continue
+				}
+				field := importedField{Name: scaffold.CamelCase(column.name),
+					Mandatory: column.notNull || column.primaryKey}
+				var mapping string
+				field.Type, field.MaxLength, mapping = sqlFieldType(column)
+				if mapping != "" {
+					note(column.line, "%s.%s is %s, which is imported as %s",
+						tableName, column.name, mapping, field.Type)
+				}
+				if !strings.EqualFold(field.Name, column.name) {
+					// MySQL column names are not case sensitive.
+					field.Column = column.name
+					note(column.line, "%s.%s becomes the field %s, with \"column\": %q",
+						tableName, column.name, field.Name, column.name)
+				}
+				resource.Fields = append(resource.Fields, field)
+			}
+			switch {
+			case len(primaryKey) == 0:
+				note(statement[0].line, "%s has no primary key - the generated table has an id column",
+					tableName)
+			case len(primaryKey) > 1 || !strings.EqualFold(primaryKey[0], "id"):
+				note(statement[0].line, "the primary key of %s is (%s) - the generated table has an id column instead",
+					tableName, strings.Join(primaryKey, ", "))
+			}
+			resources = append(resources, resource)
+		}
+	}
+
+	if len(resources) == 0 {
+		return importedSpec{}, nil, fmt.Errorf("%s: there are no CREATE TABLE statements",
+			schemaFile)
+	}
+	spec := newImportedSpec(projectName)
+	spec.Resources = resources
+	return spec, notes, nil
+}
+
+// sqlTokens splits the text of a schema into tokens, dropping the comments.
+func sqlTokens(text string) ([]sqlToken, error) {
+	tokens := make([]sqlToken, 0)
+	runes := []rune(text)
+	line := 1
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '#' || r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// A comment to the end of the line.
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			start := line
+			i += 2
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				if runes[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%d: the comment is not closed", start)
+			}
+			i += 2
+		case r == '`' || r == '"' || r == '\'':
+			// A quoted name or a string.  A quote is doubled to include it.
+			closing := r
+			start := line
+			var value strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("%d: the quotes are not closed", start)
+				}
+				if runes[i] == closing {
+					if i+1 < len(runes) && runes[i+1] == closing {
+						value.WriteRune(closing)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				if runes[i] == '\\' && closing == '\'' && i+1 < len(runes) {
+					i++
+				}
+				if runes[i] == '\n' {
+					line++
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, sqlToken{value.String(), true, start})
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) ||
+				runes[i] == '_' || runes[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{string(runes[start:i]), false, line})
+		default:
+			tokens = append(tokens, sqlToken{string(r), false, line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// sqlStatements splits the tokens into statements at each semicolon.
+func sqlStatements(tokens []sqlToken) [][]sqlToken {
+	statements := make([][]sqlToken, 0)
+	start := 0
+	for i, token := range tokens {
+		if token.text == ";" && !token.quoted {
+			if i > start {
+				statements = append(statements, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		statements = append(statements, tokens[start:])
+	}
+	return statements
+}
+
+// sqlObjectName returns the name at the start of the tokens, skipping
+// "IF NOT EXISTS" and any schema name: "public.orders" => "orders".
+func sqlObjectName(tokens []sqlToken) string {
+	if len(tokens) >= 3 && tokens[0].is("if") && tokens[1].is("not") && tokens[2].is("exists") {
+		tokens = tokens[3:]
+	}
+	name := ""
+	for i, token := range tokens {
+		if i%2 == 1 {
+			if token.text != "." || token.quoted {
+				break
+			}
+			continue
+		}
+		name = token.text
+	}
+	return name
+}
+
+// sqlCreateTable returns the name of the table that a CREATE TABLE statement
+// creates and its column and constraint definitions, or "" if the statement
+// creates something else or copies another table.  It returns an error if a
+// definition is empty, as in "name varchar(40),, age int".
+func sqlCreateTable(statement []sqlToken) (string, [][]sqlToken, error) {
+	// Skip CREATE [OR REPLACE] [TEMPORARY | UNLOGGED ...] TABLE.
+	i := 1
+	for i < len(statement) && !statement[i].is("table") {
+		switch strings.ToLower(statement[i].text) {
+		case "or", "replace", "temporary", "temp", "unlogged", "global", "local":
+			i++
+		default:
+			return "", nil, nil
+		}
+	}
+	i++
+
+	// The name ends at the opening bracket of the definitions.
+	open := i
+	for open < len(statement) && statement[open].text != "(" {
+		open++
+	}
+	if open >= len(statement) || open == i {
+		return "", nil, nil
+	}
+	name := sqlObjectName(statement[i:open])
+
+	// Split the definitions at the commas between them.
+	definitions := make([][]sqlToken, 0)
+	depth := 0
+	start := open + 1
+	for j := open; j < len(statement); j++ {
+		token := statement[j]
+		if token.quoted {
+			continue
+		}
+		switch token.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				switch {
+				case j > start:
+					definitions = append(definitions, statement[start:j])
+				case len(definitions) > 0:
+					// A comma before the closing bracket.
+					return "", nil, fmt.Errorf("%d: table %s has an empty definition",
+						token.line, name)
+				}
+				return name, definitions, nil
+			}
+		case ",":
+			if depth == 1 {
+				if j == start {
+					return "", nil, fmt.Errorf("%d: table %s has an empty definition",
+						token.line, name)
+				}
+				definitions = append(definitions, statement[start:j])
+				start = j + 1
+			}
+		}
+	}
+	return name, definitions, nil
+}
+
+// isTableConstraint returns true if the definition is a constraint or an
+// index rather than a column.
+func isTableConstraint(definition []sqlToken) bool {
+	switch strings.ToLower(definition[0].text) {
+	case "primary", "key", "index", "unique", "constraint", "foreign", "check",
+		"fulltext", "spatial", "exclude", "like":
+		return !definition[0].quoted
+	}
+	return false
+}
+
+// sqlColumnList returns the names in the first bracketed list in the
+// definition, for example the columns of a primary key.
+func sqlColumnList(definition []sqlToken) []string {
+	names := make([]string, 0)
+	inList := false
+	for _, token := range definition {
+		switch {
+		case token.text == "(" && !token.quoted:
+			inList = true
+		case token.text == ")" && !token.quoted:
+			if inList {
+				return names
+			}
+		case inList && token.text != ",":
+			names = append(names, token.text)
+		}
+	}
+	return names
+}
+
+// sqlColumnDefinition reads the definition of a column.
+func sqlColumnDefinition(definition []sqlToken) sqlColumn {
+	column := sqlColumn{name: definition[0].text, line: definition[0].line}
+	i := 1
+	if i < len(definition) {
+		column.typeName = strings.ToLower(definition[i].text)
+		i++
+	}
+	// Some types have two words.
+	if i < len(definition) && (column.typeName == "double" && definition[i].is("precision") ||
+		column.typeName == "character" && definition[i].is("varying")) {
+		column.typeName += " " + strings.ToLower(definition[i].text)
+		i++
+	}
+	if i < len(definition) && definition[i].text == "(" && !definition[i].quoted {
+		for i++; i < len(definition) && definition[i].text != ")"; i++ {
+			if definition[i].text != "," {
+				column.arguments = append(column.arguments, definition[i].text)
+			}
+		}
+		i++
+	}
+
+	for ; i < len(definition); i++ {
+		token := definition[i]
+		switch {
+		case token.is("unsigned"):
+			column.unsigned = true
+		case token.text == "[" && !token.quoted:
+			// A Postgres array, such as "integer[]".
+			column.array = true
+		case token.is("not") && i+1 < len(definition) && definition[i+1].is("null"):
+			column.notNull = true
+			i++
+		case token.is("primary") && i+1 < len(definition) && definition[i+1].is("key"):
+			column.primaryKey = true
+			i++
+		}
+	}
+	return column
+}
+
+// sqlFieldType returns the field type for a column and its maximum length,
+// if it has one.  If the column's type can't be represented exactly, it also
+// returns a description of the type.
+func sqlFieldType(column sqlColumn) (string, int, string) {
+	description := column.typeName
+	if len(column.arguments) > 0 {
+		description += "(" + strings.Join(column.arguments, ",") + ")"
+	}
+	if column.array {
+		return "string", 0, description + "[]"
+	}
+
+	switch column.typeName {
+	case "tinyint":
+		// MySQL uses tinyint(1) for booleans.
+		if len(column.arguments) == 1 && column.arguments[0] == "1" {
+			return "bool", 0, ""
+		}
+		fallthrough
+	case "smallint", "mediumint", "int", "integer", "bigint", "int2", "int4", "int8",
+		"serial", "smallserial", "bigserial", "serial4", "serial8":
+		if column.unsigned {
+			return "uint", 0, ""
+		}
+		return "int", 0, ""
+	case "bool", "boolean":
+		return "bool", 0, ""
+	case "bit":
+		if len(column.arguments) == 0 || column.arguments[0] == "1" {
+			return "bool", 0, ""
+		}
+		return "uint", 0, description
+	case "float", "double", "double precision", "real", "float4", "float8":
+		return "float", 0, ""
+	case "decimal", "numeric", "dec", "fixed", "money":
+		// Stored exactly in the database, but not in a float.
+		return "float", 0, description
+	case "char", "varchar", "character", "character varying", "nchar", "nvarchar":
+		if len(column.arguments) == 1 {
+			if maxLength, err := strconv.Atoi(column.arguments[0]); err == nil {
+				return "string", maxLength, ""
+			}
+		}
+		return "string", 0, ""
+	case "text", "tinytext", "mediumtext", "longtext", "citext":
+		return "string", 0, ""
+	}
+	// Dates, times, enums, JSON, binary data and so on.
+	return "string", 0, description
+}
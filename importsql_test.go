@@ -0,0 +1,158 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportSQL(t *testing.T) {
+	const schema = `
+-- A MySQL dump.
+CREATE DATABASE IF NOT EXISTS pet_shop;
+USE pet_shop;
+
+CREATE TABLE ` + "`movies`" + ` (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  Title varchar(40) NOT NULL,
+  rating tinyint(1),
+  release_year int unsigned,
+  price decimal(6,2),
+  PRIMARY KEY (id)
+) ENGINE=InnoDB;
+
+/* Postgres. */
+CREATE TABLE public.customer_orders (
+  order_ref text PRIMARY KEY,
+  weight double precision,
+  tags text[]
+);
+
+CREATE INDEX orders_by_weight ON customer_orders (weight);
+`
+	spec, notes, err := importSQL("schema.sql", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Name != "petShop" {
+		t.Errorf("want the project petShop, got %s", spec.Name)
+	}
+
+	want := []importedResource{
+		{Name: "movie", Fields: []importedField{
+			{Name: "title", Type: "string", Mandatory: true, MaxLength: 40},
+			{Name: "rating", Type: "bool"},
+			{Name: "releaseYear", Type: "uint", Column: "release_year"},
+			{Name: "price", Type: "float"},
+		}},
+		{Name: "customerOrder", TableName: "customer_orders", Fields: []importedField{
+			{Name: "orderRef", Type: "string", Mandatory: true, Column: "order_ref"},
+			{Name: "weight", Type: "float"},
+			{Name: "tags", Type: "string"},
+		}},
+	}
+	if !reflect.DeepEqual(spec.Resources, want) {
+		t.Errorf("want resources\n%+v\ngot\n%+v", want, spec.Resources)
+	}
+
+	// MySQL column names are not case sensitive, so Title needs no column.
+	for _, note := range notes {
+		if strings.Contains(note, "movies.Title becomes") {
+			t.Errorf("unexpected note %q", note)
+		}
+	}
+
+	wantNotes := []string{
+		`schema.sql:10: movies.release_year becomes the field releaseYear, with "column": "release_year"`,
+		"schema.sql:11: movies.price is decimal(6,2), which is imported as float",
+		`schema.sql:17: customer_orders.order_ref becomes the field orderRef, with "column": "order_ref"`,
+		"schema.sql:19: customer_orders.tags is text[], which is imported as string",
+		"schema.sql:16: the primary key of customer_orders is (order_ref)",
+	}
+	for _, wantNote := range wantNotes {
+		found := false
+		for _, note := range notes {
+			if strings.HasPrefix(note, wantNote) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing note %q in\n%s", wantNote, strings.Join(notes, "\n"))
+		}
+	}
+}
+
+func TestImportSQLErrors(t *testing.T) {
+	var testData = []struct {
+		description string
+		schema      string
+		want        string
+	}{
+		{"no tables", "CREATE INDEX i ON t (a);", "schema.sql: there are no CREATE TABLE statements"},
+		{"open comment", "CREATE TABLE t (a int);\n/* comment", "schema.sql:2: the comment is not closed"},
+		{"open quotes", "CREATE TABLE `t (a int);", "schema.sql:1: the quotes are not closed"},
+		{"two commas", "CREATE TABLE t (\n  a int,\n  b int,,\n  c int\n);", "schema.sql:3: table t has an empty definition"},
+		{"leading comma", "CREATE TABLE t (, a int);", "schema.sql:1: table t has an empty definition"},
+		{"trailing comma", "CREATE TABLE t (\n  a int,\n);", "schema.sql:3: table t has an empty definition"},
+	}
+
+	for _, td := range testData {
+		_, _, err := importSQL("schema.sql", td.schema)
+		if err == nil {
+			t.Errorf("%s: want an error", td.description)
+			continue
+		}
+		if err.Error() != td.want {
+			t.Errorf("%s: want %q, got %q", td.description, td.want, err.Error())
+		}
+	}
+}
+
+func TestSQLFieldType(t *testing.T) {
+	var testData = []struct {
+		column      sqlColumn
+		fieldType   string
+		maxLength   int
+		description string
+	}{
+		{sqlColumn{typeName: "varchar", arguments: []string{"40"}}, "string", 40, ""},
+		{sqlColumn{typeName: "character varying"}, "string", 0, ""},
+		{sqlColumn{typeName: "tinyint", arguments: []string{"1"}}, "bool", 0, ""},
+		{sqlColumn{typeName: "tinyint", arguments: []string{"4"}}, "int", 0, ""},
+		{sqlColumn{typeName: "bigint", unsigned: true}, "uint", 0, ""},
+		{sqlColumn{typeName: "bit", arguments: []string{"8"}}, "uint", 0, "bit(8)"},
+		{sqlColumn{typeName: "double precision"}, "float", 0, ""},
+		{sqlColumn{typeName: "numeric", arguments: []string{"10", "2"}}, "float", 0, "numeric(10,2)"},
+		{sqlColumn{typeName: "integer", array: true}, "string", 0, "integer[]"},
+		{sqlColumn{typeName: "date"}, "string", 0, "date"},
+	}
+
+	for _, td := range testData {
+		fieldType, maxLength, description := sqlFieldType(td.column)
+		if fieldType != td.fieldType || maxLength != td.maxLength || description != td.description {
+			t.Errorf("%s: want %s, %d, %q, got %s, %d, %q", td.column.typeName,
+				td.fieldType, td.maxLength, td.description, fieldType, maxLength, description)
+		}
+	}
+}
+
+func TestResourceForTable(t *testing.T) {
+	var testData = []struct {
+		table string
+		want  importedResource
+	}{
+		{"cats", importedResource{Name: "cat"}},
+		{"movies", importedResource{Name: "movie"}},
+		{"people", importedResource{Name: "person"}},
+		{"customer_orders", importedResource{Name: "customerOrder", TableName: "customer_orders"}},
+		{"news", importedResource{Name: "news"}},
+	}
+
+	for _, td := range testData {
+		got := resourceForTable(td.table)
+		got.Fields = nil
+		if !reflect.DeepEqual(got, td.want) {
+			t.Errorf("%s: want %+v, got %+v", td.table, td.want, got)
+		}
+	}
+}
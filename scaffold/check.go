@@ -0,0 +1,636 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The spec is checked before anything is generated, and the "check" command
+// checks it without generating anything.  Each problem is reported with its
+// line and column in the spec file, in the same form as the Go compiler's
+// messages:
+//
+//	scaffold.json:12:17: test value "ten" of field "age" is not a whole number
+//
+// so editors and CI systems can find it.  All of the problems are reported in
+// one go, except that a syntax error stops the check.
+
+// fieldTypes are the types that a field can have in the spec.
+var fieldTypes = []string{"string", "int", "uint", "float", "bool"}
+
+// A Problem is something wrong with the spec, or with the Go code generated
+// from it.  Position says where it is in the file, in the form
+// "file:line:column" (or just the file if that's all that's known).  A
+// warning doesn't stop the scaffolder.
+type Problem struct {
+	Position string
+	Message  string
+	Warning  bool
+	path     string // for example "resources[0].fields[2].name"
+	offset   int64  // the position in the file, -1 if not known
+}
+
+// String returns the problem in the form "file:line:column: message".  A
+// problem found by Enhance has no position, so it gives the path instead.
+func (p Problem) String() string {
+	result := p.Message
+	if p.Warning {
+		result = "warning: " + result
+	}
+	switch {
+	case p.Position != "":
+		result = p.Position + ": " + result
+	case p.path != "":
+		result = p.path + ": " + result
+	}
+	return result
+}
+
+// Problems is a list of problems.  As an error, it's the list of problems,
+// one per line.
+type Problems []Problem
+
+// Errors returns the number of problems that are not warnings.
+func (p Problems) Errors() int {
+	count := 0
+	for _, problem := range p {
+		if !problem.Warning {
+			count++
+		}
+	}
+	return count
+}
+
+// Error returns the problems, one per line.
+func (p Problems) Error() string {
+	lines := make([]string, len(p))
+	for i, problem := range p {
+		lines[i] = problem.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LoadSpec reads the spec file, parses and checks it and merges in any spec
+// files that it includes.  It returns the spec, ready for Enhance, and the
+// problems that it found, each with its position in the file.  If any of the
+// problems is an error, the spec can't be used.  The error is only set if the
+// spec file can't be read.
+func LoadSpec(fsys FS, specFile string) (Spec, Problems, error) {
+	data, err := fsys.ReadFile(specFile)
+	if err != nil {
+		return Spec{}, nil, fmt.Errorf("%s: cannot read the spec - %s", specFile,
+			err.Error())
+	}
+	spec, problems := loadSpec(fsys, specFile, data)
+	return spec, problems, nil
+}
+
+// loadSpec parses and checks the text of the spec file and merges in any spec
+// files that it includes.  It returns the spec and the problems that it found.
+func loadSpec(fsys FS, specFile string, data []byte) (Spec, Problems) {
+	spec, source, problems := parseSpec(specFile, data)
+	if source == nil {
+		return spec, problems
+	}
+
+	// The problems with the resources from an included file are reported in
+	// that file.
+	origins, fragments, includeProblems := includeFragments(fsys, source, &spec)
+
+	// The field sets and custom types are expanded first, so that the rest of
+	// the checks only see ordinary fields.  The problems with a field from a
+	// field set are reported where the resource uses the set.
+	fieldOrigins, fieldProblems := expandFields(&spec)
+	problems = append(problems, fieldProblems...)
+
+	// Some of the checks need the derived fields, so they are done on a copy,
+	// and the spec is returned as it was written.
+	problems = append(problems, checkSpec(spec)...)
+	enhanced := copySpec(spec)
+	problems = append(problems, enhanceSpec(&enhanced)...)
+	problems = append(problems, validateSpec(enhanced)...)
+
+	byFragment := make(map[*specSource][]Problem)
+	for _, problem := range problems {
+		fieldOrigins.move(&problem)
+		if fragment := origins.move(&problem); fragment != nil {
+			byFragment[fragment] = append(byFragment[fragment], problem)
+			continue
+		}
+		source.problems = append(source.problems, problem)
+	}
+
+	result := source.positionProblems()
+	result = append(result, includeProblems...)
+	for _, fragment := range fragments {
+		fragment.problems = append(fragment.problems, byFragment[fragment]...)
+		result = append(result, fragment.positionProblems()...)
+	}
+	return spec, result
+}
+
+// Enhance checks the spec and sets its derived fields, such as the various
+// forms of each name, ready for Generate.  It also creates any test values
+// that the spec doesn't supply.  The checks are the ones that LoadSpec makes,
+// so they are only likely to fail for a spec built by a program.  If there
+// are any errors, it returns the problems (a Problems), which have the path
+// of each problem rather than its position, and the spec can't be used.  A
+// spec built by a program can use field sets and custom types, but not
+// includes, which are only read by LoadSpec.
+func Enhance(spec *Spec) error {
+	_, problems := expandFields(spec)
+	problems = append(problems, checkSpec(*spec)...)
+	problems = append(problems, enhanceSpec(spec)...)
+	problems = append(problems, validateSpec(*spec)...)
+	if Problems(problems).Errors() > 0 {
+		return Problems(problems)
+	}
+	spec.enhanced = true
+	return nil
+}
+
+// copySpec returns a copy of the spec that can be enhanced without changing
+// the original.
+func copySpec(spec Spec) Spec {
+	spec.Resources = append([]Resource(nil), spec.Resources...)
+	for i := range spec.Resources {
+		fields := append([]Field(nil), spec.Resources[i].Fields...)
+		for j := range fields {
+			fields[j].TestValues = append([]string(nil), fields[j].TestValues...)
+		}
+		spec.Resources[i].Fields = fields
+	}
+	return spec
+}
+
+// A specSource is a spec file that has been parsed, with what's needed to
+// report the problems in it at their positions.
+type specSource struct {
+	file            string
+	data            []byte                    // the text, converted to JSON
+	positions       map[string]int64          // the offset of each item in data
+	sourcePositions map[string]sourcePosition // for a YAML or TOML file, else nil
+	problems        []Problem
+}
+
+// positionProblems sets the position of each of the problems with the file
+// and returns them, sorted into the order of their positions.
+func (s *specSource) positionProblems() []Problem {
+	if s.sourcePositions != nil {
+		return sourcePositionProblems(s.file, s.positions, s.sourcePositions,
+			s.problems)
+	}
+	return positionProblems(s.file, s.data, s.positions, s.problems)
+}
+
+// parseSpec parses the text of a spec file and returns the spec that it
+// describes, without checking its settings.  It also returns the file as a
+// specSource holding the problems found so far, which don't have their
+// positions set.  If the file can't be parsed at all, it returns a nil
+// specSource and the problems with their positions set.
+func parseSpec(specFile string, data []byte) (Spec, *specSource, []Problem) {
+	var spec Spec
+
+	// A YAML or TOML spec is converted to JSON.  The problems are reported at
+	// their positions in the original.
+	data, sourcePositions, problem := convertSpec(specFile, data)
+	if problem != nil {
+		return spec, nil, []Problem{*problem}
+	}
+
+	// A syntax error makes the rest of the checks meaningless.
+	var anything interface{}
+	err := json.Unmarshal(data, &anything)
+	if err != nil {
+		var syntaxError *json.SyntaxError
+		if errors.As(err, &syntaxError) {
+			problem := Problem{offset: syntaxError.Offset - 1,
+				Message: syntaxMessage(data, syntaxError)}
+			return spec, nil, positionProblems(specFile, data, nil, []Problem{problem})
+		}
+		problem := Problem{offset: -1, Message: err.Error()}
+		return spec, nil, positionProblems(specFile, data, nil, []Problem{problem})
+	}
+
+	scanner := keyScanner{data: data, decoder: json.NewDecoder(bytes.NewReader(data)),
+		positions: make(map[string]int64)}
+	err = scanner.scanValue("", reflect.TypeOf(spec))
+	if err != nil {
+		problem := Problem{offset: -1, Message: err.Error()}
+		return spec, nil, positionProblems(specFile, data, nil, []Problem{problem})
+	}
+	source := specSource{file: specFile, data: data, positions: scanner.positions,
+		sourcePositions: sourcePositions, problems: scanner.problems}
+
+	// json.Unmarshal carries on after a value of the wrong type, but only
+	// reports the first.
+	err = json.Unmarshal(data, &spec)
+	if err != nil {
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &typeError) {
+			// The field is given as "resources.1.fields.0.mandatory".
+			path := arrayIndex.ReplaceAllString(typeError.Field, "[$1]")
+			key := path[strings.LastIndex(path, ".")+1:]
+			source.problems = append(source.problems, Problem{path: path,
+				offset: typeError.Offset - 1,
+				Message: fmt.Sprintf("%q should be %s, not %s",
+					key, jsonTypeName(typeError.Type), typeError.Value)})
+		} else {
+			source.problems = append(source.problems,
+				Problem{offset: -1, Message: err.Error()})
+		}
+	}
+
+	return spec, &source, nil
+}
+
+// arrayIndex matches an array index in a path from the JSON decoder.
+var arrayIndex = regexp.MustCompile(`\.([0-9]+)`)
+
+// positionProblems sets the position of each problem and sorts them into the
+// order of their positions.
+func positionProblems(specFile string, data []byte, positions map[string]int64,
+	problems []Problem) []Problem {
+
+	offsets := make(map[int]int64)
+	for i, problem := range problems {
+		offset := problem.offset
+		if problem.path != "" {
+			if pathOffset := pathOffset(positions, problem.path); pathOffset >= 0 {
+				offset = pathOffset
+			}
+		}
+		offsets[i] = offset
+		if offset < 0 {
+			problems[i].Position = specFile
+			continue
+		}
+		line, column := lineAndColumn(data, offset)
+		problems[i].Position = fmt.Sprintf("%s:%d:%d", specFile, line, column)
+	}
+
+	order := make([]int, len(problems))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return offsets[order[i]] < offsets[order[j]]
+	})
+	sorted := make([]Problem, len(problems))
+	for i, k := range order {
+		sorted[i] = problems[k]
+	}
+	return sorted
+}
+
+// pathOffset returns the offset in the file of the item with the given path,
+// or of its nearest parent if it's not in the file (for example because it's
+// missing), or -1.
+func pathOffset(positions map[string]int64, path string) int64 {
+	for path != "" {
+		if offset, ok := positions[path]; ok {
+			return offset
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return -1
+}
+
+// lineAndColumn converts an offset in the data to a line and column, both
+// counting from 1.
+func lineAndColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// syntaxMessage turns a JSON syntax error into a friendlier message.  The
+// commonest mistakes are a missing comma and a comma after the last item of a
+// list, which the JSON decoder describes in terms of what it was expecting.
+func syntaxMessage(data []byte, syntaxError *json.SyntaxError) string {
+	message := "syntax error - " + syntaxError.Error()
+	previous := bytes.TrimRight(data[:syntaxError.Offset-1], " \t\r\n")
+	switch {
+	case strings.Contains(syntaxError.Error(), "after object key:value pair"),
+		strings.Contains(syntaxError.Error(), "after array element"):
+		message += " (is there a comma missing?)"
+	case syntaxError.Offset >= int64(len(data)):
+		message += " (is there a closing } or ] missing?)"
+	case len(previous) > 0 && previous[len(previous)-1] == ',':
+		message += " (there should not be a comma after the last item)"
+	}
+	return message
+}
+
+// jsonTypeName describes a Go type in JSON terms.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "a whole number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number of zero or more"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "a " + t.String()
+}
+
+// keyScanner walks through the JSON text of the spec, recording the position
+// of every item and reporting any keys that don't match a field in the
+// corresponding structure.  Those keys would otherwise be ignored, which can
+// hide a typing error.
+type keyScanner struct {
+	data      []byte
+	decoder   *json.Decoder
+	positions map[string]int64 // the offset of each item, keyed by path
+	problems  []Problem
+}
+
+// start returns the offset of the next token.
+func (s *keyScanner) start() int64 {
+	offset := s.decoder.InputOffset()
+	for offset < int64(len(s.data)) && strings.IndexByte(" \t\r\n,:", s.data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// scanValue scans the next value, which is to be decoded into a value of the
+// given type.  If the type is nil, the keys are not checked.
+func (s *keyScanner) scanValue(path string, t reflect.Type) error {
+	s.positions[path] = s.start()
+	token, err := s.decoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		for s.decoder.More() {
+			keyOffset := s.start()
+			token, err := s.decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := token.(string)
+			name, valueType, known := jsonKey(t, key)
+			if !known {
+				s.problems = append(s.problems, Problem{offset: keyOffset,
+					Message: fmt.Sprintf("unknown key %q", key)})
+			}
+			child := name
+			if path != "" {
+				child = path + "." + name
+			}
+			err = s.scanValue(child, valueType)
+			if err != nil {
+				return err
+			}
+			// Problems with an item are reported at its key.
+			s.positions[child] = keyOffset
+		}
+	case '[':
+		var elementType reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elementType = t.Elem()
+		}
+		for i := 0; s.decoder.More(); i++ {
+			err := s.scanValue(fmt.Sprintf("%s[%d]", path, i), elementType)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// The closing delimiter.
+	_, err = s.decoder.Token()
+	return err
+}
+
+// jsonKey finds the field of the type that the JSON decoder would use for
+// the key and returns its name in the JSON and its type.  Like the decoder,
+// it prefers an exact match but accepts one that differs only in case.  Only
+// fields with a JSON tag can be set from the spec - the rest are derived.  If
+// the type is nil or not a structure, any key is accepted.
+func jsonKey(t reflect.Type, key string) (string, reflect.Type, bool) {
+	if t == nil {
+		return key, nil, true
+	}
+	if t.Kind() == reflect.Map {
+		return key, t.Elem(), true
+	}
+	if t.Kind() != reflect.Struct {
+		return key, nil, true
+	}
+
+	var folded reflect.StructField
+	foldedName := ""
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if name == key {
+			return name, field.Type, true
+		}
+		if foldedName == "" && strings.EqualFold(name, key) {
+			folded = field
+			foldedName = name
+		}
+	}
+	if foldedName != "" {
+		return foldedName, folded.Type, true
+	}
+	return key, nil, false
+}
+
+// checkSpec checks the settings in the spec before it's enhanced: that the
+// required ones are present, that each field has a valid type and that the
+// test values suit the type.  The names are checked by validateSpec.
+func checkSpec(spec Spec) []Problem {
+	problems := make([]Problem, 0)
+	missing := func(path string, what string) {
+		problems = append(problems, Problem{path: path,
+			Message: what + " is missing"})
+	}
+
+	if spec.Name == "" {
+		missing("name", "the project name (\"name\")")
+	}
+	if spec.SourceBase == "" {
+		missing("sourcebase", "the location of the project (\"sourcebase\")")
+	}
+	switch spec.DB {
+	case "mysql":
+	case "":
+		missing("db", "the database type (\"db\")")
+	default:
+		problems = append(problems, Problem{path: "db",
+			Message: fmt.Sprintf("database type %q is not supported - it must be \"mysql\"",
+				spec.DB)})
+	}
+	if spec.DBUser == "" {
+		missing("dbuser", "the database user (\"dbuser\")")
+	}
+	if len(spec.Resources) == 0 {
+		missing("resources", "the list of resources (\"resources\")")
+	}
+
+	for i, resource := range spec.Resources {
+		path := fmt.Sprintf("resources[%d]", i)
+		if resource.Name == "" {
+			missing(path+".name", fmt.Sprintf("the name of resource %d", i+1))
+		}
+		if len(resource.Fields) == 0 {
+			missing(path+".fields", fmt.Sprintf("the list of fields of resource %q", resource.Name))
+		}
+
+		for j, field := range resource.Fields {
+			path := fmt.Sprintf("resources[%d].fields[%d]", i, j)
+			if field.Name == "" {
+				missing(path+".name", fmt.Sprintf("the name of field %d of resource %q",
+					j+1, resource.Name))
+			}
+			if field.Type == "" {
+				missing(path+".type", fmt.Sprintf("the type of field %q", field.Name))
+				continue
+			}
+			if !isFieldType(field.Type) {
+				problems = append(problems, Problem{path: path + ".type",
+					Message: fmt.Sprintf("field %q has type %q - it must be one of %s or %s, or a custom type defined in \"types\"",
+						field.Name, field.Type,
+						strings.Join(fieldTypes[:len(fieldTypes)-1], ", "),
+						fieldTypes[len(fieldTypes)-1])})
+				continue
+			}
+
+			if field.Pattern != "" || field.MaxLength != 0 {
+				if field.Type != "string" {
+					problems = append(problems, Problem{path: path + ".type",
+						Message: fmt.Sprintf("field %q has a pattern or a maximum length, but it's not a string",
+							field.Name)})
+				}
+				if _, err := regexp.Compile(field.Pattern); err != nil {
+					problems = append(problems, Problem{path: path + ".pattern",
+						Message: fmt.Sprintf("the pattern of field %q is not a valid regular expression - %s",
+							field.Name, err.Error())})
+				}
+				if field.MaxLength < 0 {
+					problems = append(problems, Problem{path: path + ".maxLength",
+						Message: fmt.Sprintf("the maximum length of field %q is less than zero",
+							field.Name)})
+				}
+				// The scaffolder can't make up test values that match a
+				// pattern.
+				if field.Pattern != "" && len(field.TestValues) < 2 {
+					problems = append(problems, Problem{path: path + ".testValues",
+						Message: fmt.Sprintf("field %q has a pattern, so it needs two test values that match it",
+							field.Name)})
+				}
+			}
+
+			if len(field.TestValues) > 2 {
+				problems = append(problems, Problem{path: path + ".testValues",
+					Message: fmt.Sprintf("field %q has %d test values - only the first two are used",
+						field.Name, len(field.TestValues)),
+					Warning: true})
+			}
+			for k, value := range field.TestValues {
+				problem := checkTestValue(field, value)
+				if problem != "" {
+					problems = append(problems, Problem{
+						path:    fmt.Sprintf("%s.testValues[%d]", path, k),
+						Message: fmt.Sprintf("test value %q of field %q %s", value, field.Name, problem)})
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// isFieldType returns true if the type is one that a field can have.
+func isFieldType(fieldType string) bool {
+	for _, t := range fieldTypes {
+		if fieldType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// The test values are copied into the generated tests as Go literals, so they
+// must have the right form for the field's type.
+var intLiteral = regexp.MustCompile(`^[-+]?[0-9]+$`)
+var floatLiteral = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// checkTestValue checks that a test value suits the type of the field.  It
+// returns a description of the problem or "".
+func checkTestValue(field Field, value string) string {
+	switch field.Type {
+	case "string":
+		if strings.ContainsAny(value, "\"\\\n") {
+			return "cannot contain a double quote, a backslash or a newline"
+		}
+		if field.Mandatory && strings.TrimSpace(value) == "" {
+			return "cannot be empty because the field is mandatory"
+		}
+		if pattern, err := regexp.Compile(field.Pattern); err == nil &&
+			!pattern.MatchString(value) {
+			return fmt.Sprintf("does not match the pattern %q", field.Pattern)
+		}
+		if field.MaxLength > 0 && len([]rune(value)) > field.MaxLength {
+			return fmt.Sprintf("is longer than %d characters", field.MaxLength)
+		}
+	case "int":
+		_, err := strconv.ParseInt(value, 10, 64)
+		if !intLiteral.MatchString(value) || err != nil {
+			return "is not a whole number"
+		}
+	case "uint":
+		_, err := strconv.ParseUint(value, 10, 64)
+		if !intLiteral.MatchString(value) || err != nil {
+			return "is not a whole number of zero or more"
+		}
+	case "float":
+		if !floatLiteral.MatchString(value) {
+			return "is not a number"
+		}
+	case "bool":
+		if value != "true" && value != "false" {
+			return "must be true or false"
+		}
+	}
+	return ""
+}
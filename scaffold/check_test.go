@@ -0,0 +1,245 @@
+package scaffold
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// checkSpecText is a spec with one resource.  %s is replaced by its fields,
+// which start at line 10, column 17.
+const checkSpecText = `{
+    "name": "animals",
+    "sourcebase": "github.com/goblimey/animals",
+    "db": "mysql",
+    "dbuser": "webuser",
+    "resources": [
+        {
+            "name": "cat",
+            "fields": [
+                %s
+            ]
+        }
+    ]
+}`
+
+// goodField is a field with no problems.
+const goodField = `{"name": "age", "type": "int"}`
+
+// checkSpecWith returns checkSpecText with the given fields.
+func checkSpecWith(fields string) string {
+	return strings.Replace(checkSpecText, "%s", fields, 1)
+}
+
+// checkProblems loads the spec text and returns its problems as strings.
+func checkProblems(t *testing.T, text string) []string {
+	t.Helper()
+	fsys := &MemFS{}
+	err := fsys.WriteFile("scaffold.json", []byte(text), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, problems, err := LoadSpec(fsys, "scaffold.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := make([]string, len(problems))
+	for i, problem := range problems {
+		result[i] = problem.String()
+	}
+	return result
+}
+
+// hasProblem returns true if one of the problems is the wanted one.
+func hasProblem(problems []string, want string) bool {
+	for _, problem := range problems {
+		if problem == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckTestValues(t *testing.T) {
+	var testData = []struct {
+		description string
+		field       string
+		want        string
+	}{
+		{"string with a quote",
+			`{"name": "name", "type": "string", "testValues": ["a\"b", "c"]}`,
+			`scaffold.json:10:67: test value "a\"b" of field "name" cannot contain a double quote, a backslash or a newline`},
+		{"empty mandatory string",
+			`{"name": "name", "type": "string", "mandatory": true, "testValues": ["a", " "]}`,
+			`scaffold.json:10:91: test value " " of field "name" cannot be empty because the field is mandatory`},
+		{"string not matching the pattern",
+			`{"name": "name", "type": "string", "pattern": "^[a-z]+$", "testValues": ["abc", "AB"]}`,
+			`scaffold.json:10:97: test value "AB" of field "name" does not match the pattern "^[a-z]+$"`},
+		{"string too long",
+			`{"name": "name", "type": "string", "maxLength": 3, "testValues": ["abcd", "ab"]}`,
+			`scaffold.json:10:83: test value "abcd" of field "name" is longer than 3 characters`},
+		{"int with a fraction",
+			`{"name": "age", "type": "int", "testValues": ["1.5", "2"]}`,
+			`scaffold.json:10:63: test value "1.5" of field "age" is not a whole number`},
+		{"int too big",
+			`{"name": "age", "type": "int", "testValues": ["9223372036854775808", "1"]}`,
+			`scaffold.json:10:63: test value "9223372036854775808" of field "age" is not a whole number`},
+		{"int too small",
+			`{"name": "age", "type": "int", "testValues": ["1", "-9223372036854775809"]}`,
+			`scaffold.json:10:68: test value "-9223372036854775809" of field "age" is not a whole number`},
+		{"negative uint",
+			`{"name": "legs", "type": "uint", "testValues": ["-1", "2"]}`,
+			`scaffold.json:10:65: test value "-1" of field "legs" is not a whole number of zero or more`},
+		{"uint too big",
+			`{"name": "legs", "type": "uint", "testValues": ["18446744073709551616", "2"]}`,
+			`scaffold.json:10:65: test value "18446744073709551616" of field "legs" is not a whole number of zero or more`},
+		{"float",
+			`{"name": "weight", "type": "float", "testValues": ["1e", "2.5"]}`,
+			`scaffold.json:10:68: test value "1e" of field "weight" is not a number`},
+		{"bool",
+			`{"name": "chipped", "type": "bool", "testValues": ["yes", "true"]}`,
+			`scaffold.json:10:68: test value "yes" of field "chipped" must be true or false`},
+		{"too many",
+			`{"name": "age", "type": "int", "testValues": ["1", "2", "3"]}`,
+			`scaffold.json:10:48: warning: field "age" has 3 test values - only the first two are used`},
+	}
+
+	// Every field type is tested.
+	for _, fieldType := range fieldTypes {
+		found := false
+		for _, td := range testData {
+			if strings.Contains(td.field, `"type": "`+fieldType+`"`) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("field type %s is not tested", fieldType)
+		}
+	}
+
+	for _, td := range testData {
+		problems := checkProblems(t, checkSpecWith(td.field))
+		if !hasProblem(problems, td.want) {
+			t.Errorf("%s: want\n%s\ngot\n%s", td.description, td.want, strings.Join(problems, "\n"))
+		}
+	}
+
+	// Good values give no problems.
+	problems := checkProblems(t, checkSpecWith(
+		`{"name": "name", "type": "string", "mandatory": true, "testValues": ["a", "b"]},
+                {"name": "age", "type": "int", "testValues": ["-9223372036854775808", "+7"]},
+                {"name": "legs", "type": "uint", "testValues": ["0", "18446744073709551615"]},
+                {"name": "weight", "type": "float", "testValues": ["1.5e3", "-2"]},
+                {"name": "chipped", "type": "bool", "testValues": ["true", "false"]}`))
+	if len(problems) > 0 {
+		t.Errorf("good test values: want no problems, got\n%s", strings.Join(problems, "\n"))
+	}
+}
+
+func TestCheckJSONTypes(t *testing.T) {
+	var testData = []struct {
+		description string
+		field       string
+		want        string
+	}{
+		{"bool", `{"name": "age", "type": "int", "mandatory": "yes"}`,
+			`scaffold.json:10:48: "mandatory" should be true or false, not string`},
+		{"int", `{"name": "name", "type": "string", "maxLength": "3"}`,
+			`scaffold.json:10:52: "maxLength" should be a whole number, not string`},
+		{"int out of range", `{"name": "name", "type": "string", "maxLength": 1e30}`,
+			`scaffold.json:10:52: "maxLength" should be a whole number, not number 1e30`},
+		{"string", `{"name": 3, "type": "string"}`,
+			`scaffold.json:10:18: "name" should be a string, not number`},
+		{"list", `{"name": "name", "type": "string", "testValues": "x"}`,
+			`scaffold.json:10:52: "testValues" should be a list, not string`},
+		{"list item", `{"name": "name", "type": "string", "testValues": [1, 2]}`,
+			`scaffold.json:10:67: "testValues[0]" should be a string, not number`},
+	}
+
+	for _, td := range testData {
+		problems := checkProblems(t, checkSpecWith(td.field))
+		if !hasProblem(problems, td.want) {
+			t.Errorf("%s: want\n%s\ngot\n%s", td.description, td.want, strings.Join(problems, "\n"))
+		}
+	}
+
+	problems := checkProblems(t, strings.Replace(checkSpecWith(goodField), `"dbuser": "webuser",`,
+		`"dbuser": "webuser", "inflections": [],`, 1))
+	want := `scaffold.json:5:26: "inflections" should be an object, not array`
+	if !hasProblem(problems, want) {
+		t.Errorf("object: want\n%s\ngot\n%s", want, strings.Join(problems, "\n"))
+	}
+}
+
+func TestJSONTypeName(t *testing.T) {
+	var testData = []struct {
+		value interface{}
+		want  string
+	}{
+		{"", "a string"},
+		{true, "true or false"},
+		{[]string{}, "a list"},
+		{[2]int{}, "a list"},
+		{Field{}, "an object"},
+		{map[string]string{}, "an object"},
+		{0, "a whole number"},
+		{int64(0), "a whole number"},
+		{uint(0), "a whole number of zero or more"},
+		{0.0, "a number"},
+		{float32(0), "a number"},
+	}
+
+	for _, td := range testData {
+		if got := jsonTypeName(reflect.TypeOf(td.value)); got != td.want {
+			t.Errorf("%T: want %q, got %q", td.value, td.want, got)
+		}
+	}
+}
+
+func TestCheckUnknownKeys(t *testing.T) {
+	var testData = []struct {
+		description string
+		text        string
+		want        string
+	}{
+		{"in the spec", strings.Replace(checkSpecWith(goodField), `"db": "mysql",`, `"db": "mysql", "dbusr": "x",`, 1),
+			`scaffold.json:4:20: unknown key "dbusr"`},
+		{"in a resource", strings.Replace(checkSpecWith(goodField), `"name": "cat",`, `"name": "cat", "tabel": "x",`, 1),
+			`scaffold.json:8:28: unknown key "tabel"`},
+		{"in a field", checkSpecWith(`{"name": "age", "type": "int", "mandatroy": true}`),
+			`scaffold.json:10:48: unknown key "mandatroy"`},
+		{"derived", checkSpecWith(`{"name": "age", "type": "int", "GoType": "int64"}`),
+			`scaffold.json:10:48: unknown key "GoType"`},
+	}
+
+	for _, td := range testData {
+		problems := checkProblems(t, td.text)
+		if !hasProblem(problems, td.want) {
+			t.Errorf("%s: want\n%s\ngot\n%s", td.description, td.want, strings.Join(problems, "\n"))
+		}
+	}
+
+	// A key that differs only in case is accepted, as it is by the decoder.
+	problems := checkProblems(t, checkSpecWith(
+		`{"Name": "age", "TYPE": "int"}`))
+	if len(problems) > 0 {
+		t.Errorf("keys in another case: want no problems, got\n%s", strings.Join(problems, "\n"))
+	}
+}
+
+func TestCheckMissingSettings(t *testing.T) {
+	problems := checkProblems(t, `{"db": "postgres", "resources": [{"fields": [{"type": "int"}, {"name": "x"}]}]}`)
+	for _, want := range []string{
+		`scaffold.json:1:1: the project name ("name") is missing`,
+		`scaffold.json:1:1: the location of the project ("sourcebase") is missing`,
+		`scaffold.json:1:2: database type "postgres" is not supported - it must be "mysql"`,
+		`scaffold.json:1:1: the database user ("dbuser") is missing`,
+		`scaffold.json:1:34: the name of resource 1 is missing`,
+		`scaffold.json:1:46: the name of field 1 of resource "" is missing`,
+		`scaffold.json:1:63: the type of field "x" is missing`,
+	} {
+		if !hasProblem(problems, want) {
+			t.Errorf("want\n%s\ngot\n%s", want, strings.Join(problems, "\n"))
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Dry run and diff mode.  In either mode the scaffolder goes through the
+// whole generation process but doesn't write anything.  Instead it reports
+// what it would do to each file.  In diff mode the report also gives a
+// unified diff of each file that would change.
+
+// diffContextLines is the number of unchanged lines shown around each change.
+const diffContextLines = 3
+
+// unifiedDiff returns the differences between the old and new content in
+// unified diff format, or "" if there are none.  The names go in the header
+// as they are, so the caller adds any "a/" and "b/" prefixes.
+func unifiedDiff(oldName string, newName string, oldContent []byte,
+	newContent []byte) string {
+
+	a := splitLines(oldContent)
+	b := splitLines(newContent)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// Walk the table to produce the edit script.  Each edit is ' ', '-' or
+	// '+' plus the line.  Where there's a choice, the deletion comes first,
+	// as in "diff -u" and "git diff".
+	type edit struct {
+		op   byte
+		line string
+	}
+	edits := make([]edit, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+
+	// Group the edits into hunks with some context around each change.
+	var result bytes.Buffer
+	for start := 0; start < len(edits); {
+		// Find the next change.
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		hunkStart := start - diffContextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+
+		// Extend the hunk until there are more than two lots of context
+		// between changes.
+		end := start
+		unchanged := 0
+		for end < len(edits) && unchanged <= 2*diffContextLines {
+			if edits[end].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			end++
+		}
+		end -= unchanged - diffContextLines
+		if unchanged < diffContextLines {
+			end = len(edits)
+		}
+
+		// Work out the line numbers of the start of the hunk.
+		oldLine, newLine := 1, 1
+		for _, e := range edits[:hunkStart] {
+			if e.op != '+' {
+				oldLine++
+			}
+			if e.op != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, e := range edits[hunkStart:end] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+
+		if result.Len() == 0 {
+			fmt.Fprintf(&result, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		fmt.Fprintf(&result, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount),
+			hunkRange(newLine, newCount))
+		for _, e := range edits[hunkStart:end] {
+			result.WriteByte(e.op)
+			result.WriteString(e.line)
+			if len(e.line) == 0 || e.line[len(e.line)-1] != '\n' {
+				result.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = end
+	}
+
+	return result.String()
+}
+
+// hunkRange formats the start and length of a hunk as in "diff -u".
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
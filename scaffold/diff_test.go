@@ -0,0 +1,61 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	var testData = []struct {
+		description string
+		oldContent  string
+		newContent  string
+		want        string
+	}{
+		{"same", "a\nb\n", "a\nb\n", ""},
+		{"both empty", "", "", ""},
+		{"change", "a\nb\nc\n", "a\nx\nc\n",
+			"--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{"deletions before insertions", "a\nb\nc\nd\n", "a\nx\ny\nd\n",
+			"--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n a\n-b\n-c\n+x\n+y\n d\n"},
+		{"add at end", "a\n", "a\nb\n",
+			"--- a/f\n+++ b/f\n@@ -1 +1,2 @@\n a\n+b\n"},
+		{"delete", "a\nb\n", "b\n",
+			"--- a/f\n+++ b/f\n@@ -1,2 +1 @@\n-a\n b\n"},
+		{"from empty", "", "a\nb\n",
+			"--- a/f\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"no newline at end", "a\n", "a",
+			"--- a/f\n+++ b/f\n@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n"},
+		{"two hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"1\nX\n3\n4\n5\n6\n7\n8\n9\n10\nY\n12\n",
+			"--- a/f\n+++ b/f\n@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n" +
+				"@@ -8,5 +8,5 @@\n 8\n 9\n 10\n-11\n+Y\n 12\n"},
+	}
+
+	for _, td := range testData {
+		got := unifiedDiff("a/f", "b/f", []byte(td.oldContent), []byte(td.newContent))
+		if got != td.want {
+			t.Errorf("%s: want\n%s\ngot\n%s", td.description, td.want, got)
+		}
+	}
+}
+
+func TestDiffModeHeaders(t *testing.T) {
+	fsys, _ := generateTestProject(t, testSpec, Options{ProjectDir: "proj"})
+	fsys.WriteFile("proj/go.mod", []byte("module x\n"), 0666)
+	spec := loadTestSpec(t, fsys, testSpec)
+	report, err := Generate(spec, Options{FS: fsys, ProjectDir: "proj", Diff: true, Overwrite: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range report.Files {
+		if file.Path == "proj/go.mod" {
+			if !strings.HasPrefix(file.Diff, "--- a/go.mod\n+++ b/go.mod\n@@ ") {
+				t.Errorf("go.mod: unexpected diff header\n%s", file.Diff)
+			}
+			return
+		}
+	}
+	t.Error("go.mod is not in the report")
+}
@@ -0,0 +1,154 @@
+package scaffold
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Many resources share the same fields (who created a record, notes, an
+// address) and many fields share the same rules (a postcode is a string of a
+// certain form).  The spec can define a field set once and mix it into any
+// number of resources, and define a custom type once and use it as the type
+// of any number of fields:
+//
+//	"fieldSets": {
+//	    "audit": [
+//	        {"name": "createdBy", "type": "string"},
+//	        {"name": "notes", "type": "string"}
+//	    ]
+//	},
+//	"types": {
+//	    "postcode": {"type": "string", "pattern": "^[A-Z0-9 ]+$", "maxLength": 8,
+//	        "testValues": ["SW1A 1AA", "M1 1AE"]}
+//	},
+//	"resources": [
+//	    {
+//	        "name": "customer", "fieldSets": ["audit"],
+//	        "fields": [{"name": "postcode", "type": "postcode", "mandatory": true}]
+//	    }
+//	]
+//
+// The fields of a set are added after the resource's own fields, in the order
+// that the sets are named.  A field of a custom type becomes a field of the
+// type that it's based on, with the custom type's pattern, maximum length and
+// test values unless the field gives its own.  Both are expanded before the
+// spec is checked, so the rest of the scaffolder and the templates only see
+// ordinary fields.
+
+// A FieldType is a custom type defined in the spec.  Type is the built-in type
+// that it's based on.  Pattern (a Go regular expression) and MaxLength
+// constrain the values of a string.
+type FieldType struct {
+	Type       string   `json:"type"`
+	Pattern    string   `json:"pattern"`
+	MaxLength  int      `json:"maxLength"`
+	TestValues []string `json:"testValues"`
+}
+
+// fieldOrigins maps the path of each field that was added from a field set,
+// such as "resources[0].fields[3]", to the path of the resource's reference to
+// the set, such as "resources[0].fieldSets[1]".  Problems with the field are
+// reported there.
+type fieldOrigins map[string]string
+
+// fieldPath matches the path of a field in a resource.
+var fieldPath = regexp.MustCompile(`^resources\[[0-9]+\]\.fields\[[0-9]+\]`)
+
+// move changes the path of a problem with a field that was added from a field
+// set to the path of the reference to the set.
+func (o fieldOrigins) move(problem *Problem) {
+	if origin, ok := o[fieldPath.FindString(problem.path)]; ok {
+		problem.path = origin
+		problem.offset = -1
+	}
+}
+
+// expandFields adds the fields from the field sets to the resources that use
+// them and replaces each custom type with the type that it's based on.  It
+// returns where the added fields came from and the problems that it found.
+// It can safely be called more than once.
+func expandFields(spec *Spec) (fieldOrigins, []Problem) {
+	problems := make([]Problem, 0)
+	origins := make(fieldOrigins)
+
+	typeNames := make([]string, 0, len(spec.Types))
+	for name := range spec.Types {
+		typeNames = append(typeNames, name)
+	}
+	sort.Strings(typeNames)
+	for _, name := range typeNames {
+		path := "types." + name
+		baseType := spec.Types[name].Type
+		switch {
+		case isFieldType(name):
+			problems = append(problems, Problem{path: path,
+				Message: fmt.Sprintf("custom type %q has the same name as a built-in type",
+					name)})
+		case baseType == "":
+			problems = append(problems, Problem{path: path,
+				Message: fmt.Sprintf("the type that custom type %q is based on (\"type\") is missing",
+					name)})
+		case !isFieldType(baseType):
+			problems = append(problems, Problem{path: path + ".type",
+				Message: fmt.Sprintf("custom type %q is based on %q - it must be one of %s or %s",
+					name, baseType,
+					strings.Join(fieldTypes[:len(fieldTypes)-1], ", "),
+					fieldTypes[len(fieldTypes)-1])})
+		}
+	}
+
+	for i := range spec.Resources {
+		resource := &spec.Resources[i]
+		for k, setName := range resource.FieldSets {
+			setPath := fmt.Sprintf("resources[%d].fieldSets[%d]", i, k)
+			fieldSet, ok := spec.FieldSets[setName]
+			if !ok {
+				problems = append(problems, Problem{path: setPath,
+					Message: fmt.Sprintf("there is no field set called %q", setName)})
+				continue
+			}
+			for _, field := range fieldSet {
+				origins[fmt.Sprintf("resources[%d].fields[%d]", i, len(resource.Fields))] =
+					setPath
+				// Each resource gets its own copy of the test values, because
+				// they are filled in later.
+				field.TestValues = append([]string(nil), field.TestValues...)
+				resource.Fields = append(resource.Fields, field)
+			}
+		}
+		// The sets have been used, so expanding the spec again does nothing.
+		resource.FieldSets = nil
+
+		for j := range resource.Fields {
+			field := &resource.Fields[j]
+			fieldType, ok := spec.Types[field.Type]
+			if !ok || isFieldType(field.Type) {
+				continue
+			}
+			field.TypeName = field.Type
+			field.Type = fieldType.Type
+			if field.Pattern == "" {
+				field.Pattern = fieldType.Pattern
+			}
+			if field.MaxLength == 0 {
+				field.MaxLength = fieldType.MaxLength
+			}
+			if len(field.TestValues) == 0 {
+				field.TestValues = append([]string(nil), fieldType.TestValues...)
+			}
+		}
+	}
+
+	return origins, problems
+}
+
+// lastRunes returns the last n runes of the string, or all of it if n is 0.
+func lastRunes(s string, n int) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[len(runes)-n:])
+}
@@ -0,0 +1,149 @@
+package scaffold
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fieldSetSpec is a spec with field sets and custom types.  %s is replaced by
+// the resources.
+const fieldSetSpec = `{
+    "name": "animals",
+    "sourcebase": "github.com/goblimey/animals",
+    "db": "mysql",
+    "dbuser": "webuser",
+    "dbpassword": "secret",
+    "dbserver": "localhost",
+    "orm": "gorp",
+    "fieldSets": {
+        "audit": [
+            {"name": "createdBy", "type": "string"},
+            {"name": "notes", "type": "string"}
+        ],
+        "address": [
+            {"name": "postcode", "type": "postcode"}
+        ]
+    },
+    "types": {
+        "postcode": {"type": "string", "pattern": "^[A-Z0-9 ]+$", "maxLength": 8,
+            "testValues": ["SW1A 1AA", "M1 1AE"]}
+    },
+    "resources": [%s]
+}`
+
+func TestExpandFields(t *testing.T) {
+	fsys := &MemFS{}
+	spec := loadTestSpec(t, fsys, strings.Replace(fieldSetSpec, "%s", `
+        {"name": "customer", "fieldSets": ["audit", "address"],
+            "fields": [{"name": "name", "type": "string", "mandatory": true}]},
+        {"name": "shop", "fieldSets": ["address"],
+            "fields": [{"name": "home", "type": "postcode", "maxLength": 10,
+                "testValues": ["AB1 2CD", "EF3 4GH"]}]}`, 1))
+
+	var testData = []struct {
+		resource int
+		want     []Field
+	}{
+		{0, []Field{
+			{Name: "name", Type: "string", Mandatory: true},
+			{Name: "createdBy", Type: "string"},
+			{Name: "notes", Type: "string"},
+			{Name: "postcode", Type: "string", TypeName: "postcode", Pattern: "^[A-Z0-9 ]+$",
+				MaxLength: 8, TestValues: []string{"SW1A 1AA", "M1 1AE"}},
+		}},
+		{1, []Field{
+			{Name: "home", Type: "string", TypeName: "postcode", Pattern: "^[A-Z0-9 ]+$",
+				MaxLength: 10, TestValues: []string{"AB1 2CD", "EF3 4GH"}},
+			{Name: "postcode", Type: "string", TypeName: "postcode", Pattern: "^[A-Z0-9 ]+$",
+				MaxLength: 8, TestValues: []string{"SW1A 1AA", "M1 1AE"}},
+		}},
+	}
+
+	for _, td := range testData {
+		resource := spec.Resources[td.resource]
+		if len(resource.Fields) != len(td.want) {
+			t.Errorf("%s: want %d fields, got %d", resource.Name, len(td.want), len(resource.Fields))
+			continue
+		}
+		for j, want := range td.want {
+			got := resource.Fields[j]
+			if got.Name != want.Name || got.Type != want.Type || got.TypeName != want.TypeName ||
+				got.Pattern != want.Pattern || got.MaxLength != want.MaxLength ||
+				got.Mandatory != want.Mandatory {
+				t.Errorf("%s: want field\n%v\ngot\n%v", resource.Name, want, got)
+			}
+			if want.TestValues != nil && !reflect.DeepEqual(got.TestValues, want.TestValues) {
+				t.Errorf("%s.%s: want test values %v, got %v", resource.Name, want.Name,
+					want.TestValues, got.TestValues)
+			}
+		}
+	}
+
+	if !spec.Resources[0].HasPatterns {
+		t.Error("customer: the pattern from the custom type was not seen")
+	}
+}
+
+func TestExpandFieldsTwice(t *testing.T) {
+	fsys := &MemFS{}
+	fsys.WriteFile("scaffold.json", []byte(strings.Replace(fieldSetSpec, "%s",
+		`{"name": "customer", "fieldSets": ["audit"], "fields": []}`, 1)), 0644)
+	spec, problems, err := LoadSpec(fsys, "scaffold.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) > 0 {
+		t.Fatalf("problems with the spec:\n%s", problems.Error())
+	}
+	_, _ = expandFields(&spec)
+	if len(spec.Resources[0].Fields) != 2 {
+		t.Errorf("want 2 fields, got %v", spec.Resources[0].Fields)
+	}
+}
+
+func TestFieldSetProblems(t *testing.T) {
+	var testData = []struct {
+		description string
+		resources   string
+		types       string
+		want        string
+	}{
+		{"unknown set", `{"name": "cat", "fieldSets": ["audit", "adress"], "fields": []}`, "",
+			`scaffold.json:22:58: there is no field set called "adress"`},
+		{"problem in a set", `{"name": "cat", "fieldSets": ["audit"],
+            "fields": [{"name": "createdBy", "type": "string"}]}`, "",
+			`scaffold.json:22:49: field "createdBy" clashes with field "createdBy"`},
+		{"built-in name", `{"name": "cat", "fields": [{"name": "age", "type": "int"}]}`,
+			`"int": {"type": "string"}`,
+			`scaffold.json:19:9: custom type "int" has the same name as a built-in type`},
+		{"no base type", `{"name": "cat", "fields": [{"name": "age", "type": "int"}]}`,
+			`"age": {"maxLength": 3}`,
+			`scaffold.json:19:9: the type that custom type "age" is based on ("type") is missing`},
+		{"bad base type", `{"name": "cat", "fields": [{"name": "age", "type": "int"}]}`,
+			`"age": {"type": "postcode"}`,
+			`scaffold.json:19:17: custom type "age" is based on "postcode"`},
+	}
+
+	for _, td := range testData {
+		text := strings.Replace(fieldSetSpec, "%s", td.resources, 1)
+		if td.types != "" {
+			text = strings.Replace(text, `"types": {`, `"types": {`+"\n        "+td.types+",", 1)
+		}
+		fsys := &MemFS{}
+		fsys.WriteFile("scaffold.json", []byte(text), 0644)
+		_, problems, err := LoadSpec(fsys, "scaffold.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, problem := range problems {
+			if strings.HasPrefix(problem.String(), td.want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: want %s, got\n%s", td.description, td.want, problems.Error())
+		}
+	}
+}
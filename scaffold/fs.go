@@ -0,0 +1,265 @@
+package scaffold
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The scaffolder reads the spec, the user's templates and the existing files
+// in the project, and writes the generated files, through an FS.  OSFS is the
+// real file system.  A MemFS holds the files in memory, which is useful for
+// tests and for a program that wants to look at the results before writing
+// them anywhere.
+
+// FS is a file system.  The names are in the form used by the path/filepath
+// package.  WriteFile creates any directories that the file needs, and the
+// permissions only apply to a new file, as with ioutil.WriteFile.  Remove
+// removes a file or an empty directory.  An error for a file that doesn't
+// exist satisfies os.IsNotExist.
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+	Remove(name string) error
+	Chmod(name string, mode fs.FileMode) error
+}
+
+// OSFS is the real file system.
+var OSFS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(name), 0777)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, data, perm)
+}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFS) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+// A MemFS is a file system held in memory.  The zero value is an empty file
+// system, ready to use.  The names are cleaned, so "./a/b.go" and "a/b.go" are
+// the same file, but a relative name is not the same as an absolute one.
+// Directories are created as they are needed.
+type MemFS struct {
+	files map[string]*memFile
+}
+
+// memFile is a file or a directory in a MemFS.
+type memFile struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (f *memFile) Name() string               { return filepath.Base(f.name) }
+func (f *memFile) Size() int64                { return int64(len(f.data)) }
+func (f *memFile) Mode() fs.FileMode          { return f.mode }
+func (f *memFile) ModTime() time.Time         { return f.modTime }
+func (f *memFile) IsDir() bool                { return f.mode.IsDir() }
+func (f *memFile) Sys() interface{}           { return nil }
+func (f *memFile) Type() fs.FileMode          { return f.mode.Type() }
+func (f *memFile) Info() (fs.FileInfo, error) { return f, nil }
+
+// Files returns the names of the files (not the directories) in the file
+// system, sorted.
+func (m *MemFS) Files() []string {
+	names := make([]string, 0, len(m.files))
+	for name, file := range m.files {
+		if !file.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	file, err := m.find("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return append([]byte(nil), file.data...), nil
+}
+
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = filepath.Clean(name)
+	err := m.mkdirAll(filepath.Dir(name))
+	if err != nil {
+		return err
+	}
+	file, ok := m.files[name]
+	switch {
+	case !ok:
+		file = &memFile{name: name, mode: perm.Perm()}
+		m.files[name] = file
+	case file.IsDir():
+		return &fs.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	}
+	file.data = append([]byte(nil), data...)
+	file.modTime = time.Now()
+	return nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	dir, err := m.find("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if !dir.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries := make([]fs.DirEntry, 0)
+	for _, file := range m.files {
+		if file.name != dir.name && filepath.Dir(file.name) == dir.name {
+			entries = append(entries, file)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	return m.find("stat", name)
+}
+
+func (m *MemFS) Remove(name string) error {
+	file, err := m.find("remove", name)
+	if err != nil {
+		return err
+	}
+	if file.IsDir() {
+		for _, other := range m.files {
+			if other.name != file.name && filepath.Dir(other.name) == file.name {
+				return &fs.PathError{Op: "remove", Path: name,
+					Err: errors.New("directory not empty")}
+			}
+		}
+	}
+	delete(m.files, file.name)
+	return nil
+}
+
+func (m *MemFS) Chmod(name string, mode fs.FileMode) error {
+	file, err := m.find("chmod", name)
+	if err != nil {
+		return err
+	}
+	file.mode = file.mode.Type() | mode.Perm()
+	return nil
+}
+
+// find returns the named file or directory, or an error for the operation.
+func (m *MemFS) find(op string, name string) (*memFile, error) {
+	m.init()
+	file, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return file, nil
+}
+
+// mkdirAll creates the directory and its parents.
+func (m *MemFS) mkdirAll(dir string) error {
+	m.init()
+	for ; ; dir = filepath.Dir(dir) {
+		if file, ok := m.files[dir]; ok {
+			if !file.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: errors.New("not a directory")}
+			}
+		} else {
+			m.files[dir] = &memFile{name: dir, mode: fs.ModeDir | 0777, modTime: time.Now()}
+		}
+		if dir == filepath.Dir(dir) {
+			return nil
+		}
+	}
+}
+
+// init makes the zero MemFS usable.  The current directory always exists.
+func (m *MemFS) init() {
+	if m.files == nil {
+		m.files = map[string]*memFile{".": {name: ".", mode: fs.ModeDir | 0777}}
+	}
+}
+
+// fileExists returns true if the named file exists and is not a directory.
+func fileExists(fsys FS, name string) bool {
+	fileInfo, err := fsys.Stat(name)
+	return err == nil && !fileInfo.IsDir()
+}
+
+// glob returns the names of the files matching the pattern, in the same way
+// as filepath.Glob but in the file system.
+func glob(fsys FS, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	if !strings.ContainsAny(pattern, `*?[\`) {
+		if _, err := fsys.Stat(pattern); err != nil {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	dir, file := filepath.Split(pattern)
+	dir = filepath.Clean(dir)
+	if dir == "" {
+		dir = "."
+	}
+	dirs := []string{dir}
+	if strings.ContainsAny(dir, `*?[\`) && dir != pattern {
+		var err error
+		dirs, err = glob(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	matches := make([]string, 0)
+	for _, dir := range dirs {
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if matched, _ := filepath.Match(file, entry.Name()); matched {
+				matches = append(matches, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	return matches, nil
+}
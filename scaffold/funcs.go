@@ -0,0 +1,168 @@
+package scaffold
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// templateFuncs is the library of functions available in every template,
+// including the user's own templates and the patterns in the output list.
+// They save the templates from depending on a precomputed field for every
+// form of a name, for example:
+//
+//	{{snake .Name}}               "CatAndDog" => "cat_and_dog"
+//	{{.Name | plural | kebab}}    "CatAndDog" => "cat-and-dogs"
+//	{{goType .Type}}              "int" => "int64"
+//	{{.TestValues | join ", "}}   ["a", "b"] => "a, b"
+var templateFuncs = template.FuncMap{
+	"camel":    CamelCase,
+	"pascal":   pascalCase,
+	"snake":    snakeCase,
+	"kebab":    kebabCase,
+	"plural":   Plural,
+	"singular": Singular,
+	"goType":   goType,
+	"sqlType":  sqlType,
+	"sqlName":  sqlName,
+	"quote":    strconv.Quote,
+	"join":     join,
+}
+
+// templateFuncsFor returns the template functions for a spec.  plural and
+// singular use the words given in the spec as well as the built-in ones.
+func templateFuncsFor(inflections Inflections) template.FuncMap {
+	in := newInflector(inflections)
+	funcs := make(template.FuncMap, len(templateFuncs))
+	for name, f := range templateFuncs {
+		funcs[name] = f
+	}
+	funcs["plural"] = in.plural
+	funcs["singular"] = in.singular
+	return funcs
+}
+
+// splitWords splits a name into words.  A word ends at a character that is
+// not a letter or a digit (which is dropped), at a change from lower case to
+// upper case and at the end of a run of capitals, so "catAndDog",
+// "cat_and_dog" and "cat-and-dog" all give "cat", "And"/"and", "Dog"/"dog"
+// and "HTTPServer" gives "HTTP", "Server".
+func splitWords(name string) []string {
+	words := make([]string, 0)
+	runes := []rune(name)
+	start := -1 // the start of the current word, -1 if none
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		previous := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsUpper(r) &&
+			(!unicode.IsUpper(previous) || nextIsLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// CamelCase returns the name in camel case: "cat_and_dog" => "catAndDog".  An
+// acronym at the start is lowered: "HTTPServer" => "httpServer".
+func CamelCase(name string) string {
+	words := splitWords(name)
+	for i, word := range words {
+		switch {
+		case i > 0:
+			words[i] = upperFirstRune(word)
+		case word == strings.ToUpper(word):
+			words[i] = strings.ToLower(word)
+		default:
+			words[i] = lowerFirstRune(word)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// pascalCase returns the name in Pascal case: "cat_and_dog" => "CatAndDog".
+func pascalCase(name string) string {
+	words := splitWords(name)
+	for i, word := range words {
+		words[i] = upperFirstRune(word)
+	}
+	return strings.Join(words, "")
+}
+
+// snakeCase returns the name in snake case: "CatAndDog" => "cat_and_dog".
+func snakeCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "_"))
+}
+
+// kebabCase returns the name in kebab case: "CatAndDog" => "cat-and-dog".
+func kebabCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// goType returns the Go type used for a field of the given type in the
+// spec.  In the JSON, the types are "int", "uint", "float", "bool" or
+// "string".  In the generated Go code use int64 for int, uint64 for uint and
+// float64 for float.  Other types are OK.
+func goType(fieldType string) string {
+	switch fieldType {
+	case "int":
+		return "int64"
+	case "uint":
+		return "uint64"
+	case "float":
+		return "float64"
+	default:
+		return fieldType
+	}
+}
+
+// sqlType returns the MySQL column type used for a field of the given type
+// in the spec, or its Go type.
+func sqlType(fieldType string) string {
+	switch fieldType {
+	case "int", "int64":
+		return "bigint"
+	case "uint", "uint64":
+		return "bigint unsigned"
+	case "float", "float64":
+		return "double"
+	case "bool":
+		return "boolean"
+	case "string":
+		return "varchar(255)"
+	default:
+		return fieldType
+	}
+}
+
+// join joins the items of a list (for example the test values of a field)
+// with a separator.  The items can be of any type.  The separator comes first
+// so that the list can be piped in: {{.TestValues | join ", "}}.
+func join(separator string, list interface{}) (string, error) {
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: cannot join a %T", list)
+	}
+	items := make([]string, value.Len())
+	for i := range items {
+		items[i] = fmt.Sprint(value.Index(i).Interface())
+	}
+	return strings.Join(items, separator), nil
+}
@@ -0,0 +1,222 @@
+package scaffold
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestNameCases(t *testing.T) {
+	var testData = []struct {
+		name   string
+		camel  string
+		pascal string
+		snake  string
+		kebab  string
+	}{
+		{"catAndDog", "catAndDog", "CatAndDog", "cat_and_dog", "cat-and-dog"},
+		{"CatAndDog", "catAndDog", "CatAndDog", "cat_and_dog", "cat-and-dog"},
+		{"cat_and_dog", "catAndDog", "CatAndDog", "cat_and_dog", "cat-and-dog"},
+		{"cat-and-dog", "catAndDog", "CatAndDog", "cat_and_dog", "cat-and-dog"},
+		{"cat and dog", "catAndDog", "CatAndDog", "cat_and_dog", "cat-and-dog"},
+		{"cat__and--dog", "catAndDog", "CatAndDog", "cat_and_dog", "cat-and-dog"},
+		{"__cat__", "cat", "Cat", "cat", "cat"},
+		{"URLPath", "urlPath", "URLPath", "url_path", "url-path"},
+		{"userID", "userID", "UserID", "user_id", "user-id"},
+		{"ID", "id", "ID", "id", "id"},
+		{"HTTP2Server", "http2Server", "HTTP2Server", "http2_server", "http2-server"},
+		{"address2Line", "address2Line", "Address2Line", "address2_line", "address2-line"},
+		{"address_2", "address2", "Address2", "address_2", "address-2"},
+		{"v2", "v2", "V2", "v2", "v2"},
+		{"café_crème", "caféCrème", "CaféCrème", "café_crème", "café-crème"},
+		{"", "", "", "", ""},
+	}
+
+	for _, td := range testData {
+		if got := CamelCase(td.name); got != td.camel {
+			t.Errorf("camel %q: want %q, got %q", td.name, td.camel, got)
+		}
+		if got := pascalCase(td.name); got != td.pascal {
+			t.Errorf("pascal %q: want %q, got %q", td.name, td.pascal, got)
+		}
+		if got := snakeCase(td.name); got != td.snake {
+			t.Errorf("snake %q: want %q, got %q", td.name, td.snake, got)
+		}
+		if got := kebabCase(td.name); got != td.kebab {
+			t.Errorf("kebab %q: want %q, got %q", td.name, td.kebab, got)
+		}
+	}
+}
+
+func TestGoAndSQLTypes(t *testing.T) {
+	var testData = []struct {
+		fieldType string
+		goType    string
+		sqlType   string
+	}{
+		{"string", "string", "varchar(255)"},
+		{"int", "int64", "bigint"},
+		{"uint", "uint64", "bigint unsigned"},
+		{"float", "float64", "double"},
+		{"bool", "bool", "boolean"},
+		{"int64", "int64", "bigint"},
+		{"uint64", "uint64", "bigint unsigned"},
+		{"float64", "float64", "double"},
+		{"date", "date", "date"},
+	}
+
+	// Every field type is in the table.
+	covered := make(map[string]bool)
+	for _, td := range testData {
+		covered[td.fieldType] = true
+	}
+	for _, fieldType := range fieldTypes {
+		if !covered[fieldType] {
+			t.Errorf("field type %s is not tested", fieldType)
+		}
+	}
+
+	for _, td := range testData {
+		if got := goType(td.fieldType); got != td.goType {
+			t.Errorf("goType %s: want %s, got %s", td.fieldType, td.goType, got)
+		}
+		if got := sqlType(td.fieldType); got != td.sqlType {
+			t.Errorf("sqlType %s: want %s, got %s", td.fieldType, td.sqlType, got)
+		}
+		// The Go type gives the same column type as the field type.
+		if got := sqlType(goType(td.fieldType)); got != td.sqlType {
+			t.Errorf("sqlType %s: want %s, got %s", goType(td.fieldType), td.sqlType, got)
+		}
+	}
+}
+
+func TestSQLName(t *testing.T) {
+	var testData = []struct {
+		name string
+		want string
+	}{
+		{"cats", "cats"},
+		{"order", "`order`"},
+		{"Order", "`Order`"},
+		{"orderRef", "orderRef"},
+	}
+
+	for _, td := range testData {
+		if got := sqlName(td.name); got != td.want {
+			t.Errorf("%s: want %s, got %s", td.name, td.want, got)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	var testData = []struct {
+		description string
+		list        interface{}
+		want        string
+	}{
+		{"strings", []string{"a", "b", "c"}, "a, b, c"},
+		{"ints", []int{1, 2}, "1, 2"},
+		{"array", [2]bool{true, false}, "true, false"},
+		{"one", []string{"a"}, "a"},
+		{"empty", []string{}, ""},
+		{"nil", []string(nil), ""},
+	}
+
+	for _, td := range testData {
+		got, err := join(", ", td.list)
+		if err != nil {
+			t.Errorf("%s: %v", td.description, err)
+			continue
+		}
+		if got != td.want {
+			t.Errorf("%s: want %q, got %q", td.description, td.want, got)
+		}
+	}
+
+	_, err := join(", ", "abc")
+	if err == nil || !strings.Contains(err.Error(), "cannot join a string") {
+		t.Errorf("want an error for a string, got %v", err)
+	}
+}
+
+func TestTemplateFuncsFor(t *testing.T) {
+	// Each function is used once, under the name that the templates use.
+	var testData = []struct {
+		function string
+		text     string
+		want     string
+	}{
+		{"camel", `{{camel "cat_and_dog"}}`, "catAndDog"},
+		{"pascal", `{{pascal "cat_and_dog"}}`, "CatAndDog"},
+		{"snake", `{{snake "CatAndDog"}}`, "cat_and_dog"},
+		{"kebab", `{{"CatAndDog" | plural | kebab}}`, "cat-and-dogs"},
+		{"plural", `{{plural "mouse"}} {{plural "octopus"}}`, "mice octopodes"},
+		{"singular", `{{singular "mice"}} {{singular "octopodes"}}`, "mouse octopus"},
+		{"goType", `{{goType "int"}}`, "int64"},
+		{"sqlType", `{{sqlType "uint"}}`, "bigint unsigned"},
+		{"sqlName", `{{sqlName "order"}}`, "`order`"},
+		{"quote", `{{quote "say \"hi\""}}`, `"say \"hi\""`},
+		{"join", `{{.TestValues | join ", "}}`, "1, 2"},
+	}
+
+	funcs := templateFuncsFor(Inflections{Irregular: map[string]string{"octopus": "octopodes"}})
+
+	// Every function is tested and there are no others.
+	tested := make([]string, 0, len(testData))
+	for _, td := range testData {
+		tested = append(tested, td.function)
+	}
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	if !sameWords(tested, names) || !sameWords(names, keys(templateFuncs)) {
+		t.Errorf("want the functions %v, got %v", tested, names)
+	}
+
+	field := Field{TestValues: []string{"1", "2"}}
+	for _, td := range testData {
+		tmpl, err := template.New(td.function).Funcs(funcs).Parse(td.text)
+		if err != nil {
+			t.Errorf("%s: %v", td.function, err)
+			continue
+		}
+		var result strings.Builder
+		err = tmpl.Execute(&result, field)
+		if err != nil {
+			t.Errorf("%s: %v", td.function, err)
+			continue
+		}
+		if result.String() != td.want {
+			t.Errorf("%s: want %q, got %q", td.function, td.want, result.String())
+		}
+	}
+
+	// The spec's words are not added to the built-in functions.
+	if got := templateFuncs["plural"].(func(string) string)("octopus"); got == "octopodes" {
+		t.Error("the spec's words leaked into the built-in plural function")
+	}
+}
+
+// keys returns the names in a FuncMap.
+func keys(funcs template.FuncMap) []string {
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	return names
+}
+
+// sameWords returns true if the two lists hold the same words, in any order.
+func sameWords(a, b []string) bool {
+	setA := make(map[string]bool)
+	for _, word := range a {
+		setA[word] = true
+	}
+	setB := make(map[string]bool)
+	for _, word := range b {
+		setB[word] = true
+	}
+	return reflect.DeepEqual(setA, setB)
+}
@@ -0,0 +1,248 @@
+// Package scaffold is the Goblimey scaffolder as a library.  The scaffolder
+// command is a thin layer on top of it, and a program can use it in the same
+// way:
+//
+//	spec, problems, err := scaffold.LoadSpec(scaffold.OSFS, "scaffold.json")
+//	... report err and the problems, stop if problems.Errors() > 0 ...
+//	err = scaffold.Enhance(&spec)
+//	... report err ...
+//	report, err := scaffold.Generate(spec, scaffold.Options{ProjectDir: "."})
+//
+// Nothing in the package exits or writes to the standard output.  Problems
+// with the spec are returned as Problems, anything else that goes wrong as an
+// error, and the files produced, any warnings and any errors in the generated
+// Go code are listed in the Report.
+// The files are read and written through an FS, so Generate can be run
+// against a MemFS in a test.
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// Options control Generate.
+type Options struct {
+	FS          FS          // the file system, OSFS if nil
+	ProjectDir  string      // the project directory, "." if empty
+	TemplateDir string      // a directory of templates to use instead of the built-in ones
+	Overwrite   bool        // overwrite all files, not just the ones that are always generated
+	DryRun      bool        // don't write any files, just report what would be done
+	Diff        bool        // like DryRun, but also give a unified diff of each file that would change
+	TypeCheck   bool        // check the generated Go packages with go/types, see gocheck.go
+	Log         *log.Logger // if set, what's being done is logged in detail
+}
+
+// The actions in a FileReport.
+const (
+	ActionCreate    = "create"    // a new file
+	ActionOverwrite = "overwrite" // an existing file, with new contents
+	ActionSame      = "same"      // an existing file, with the same contents
+	ActionSkip      = "skip"      // an existing file, left alone
+	ActionRemove    = "remove"    // a file that is no longer generated
+)
+
+// A FileReport says what Generate did, or would do in dry run mode, to one
+// file.  Diff is only set in diff mode.
+type FileReport struct {
+	Path     string // including the project directory
+	Template string // the template that produced it, "" if it's removed
+	Action   string
+	Diff     string
+}
+
+// A Report lists what Generate did to each file, in the order that it did
+// it, and any warnings.  Problems are the errors in the generated Go code,
+// which are bugs in the templates.  The files are produced anyway.
+type Report struct {
+	Files    []FileReport
+	Warnings []string
+	Problems Problems
+}
+
+// generator holds the state of a run of Generate.
+type generator struct {
+	Options
+	templates        map[string]*template.Template
+	funcs            template.FuncMap  // the template functions, for the spec
+	packTemplates    map[string]string // see createTemplates
+	previousManifest Manifest          // written by the previous run
+	manifestEntries  map[string]ManifestEntry
+	createOnce       map[string]bool   // the templates of the "ifmissing" outputs
+	contents         map[string][]byte // the files produced by this run, by path
+	report           Report
+}
+
+// Generate produces the project described by the enhanced spec, using the
+// output list and the templates, and returns a report of what it did.  The
+// spec must have been through Enhance.
+func Generate(spec Spec, options Options) (Report, error) {
+	if !spec.enhanced {
+		return Report{}, errors.New("the spec has not been enhanced - call Enhance before Generate")
+	}
+	if options.FS == nil {
+		options.FS = OSFS
+	}
+	if options.ProjectDir == "" {
+		options.ProjectDir = "."
+	}
+	g := generator{Options: options,
+		templates:       make(map[string]*template.Template),
+		funcs:           templateFuncsFor(spec.Inflections),
+		packTemplates:   make(map[string]string),
+		manifestEntries: make(map[string]ManifestEntry),
+		createOnce:      make(map[string]bool),
+		contents:        make(map[string][]byte)}
+
+	// Produce templates from the built-in prototypes, replacing any that the
+	// user has supplied in the project's scaffold-templates directory or in
+	// the TemplateDir directory.  The second choice is intended for use only
+	// during development of the scaffolder.
+	err := g.createTemplates()
+	if err != nil {
+		return g.report, err
+	}
+
+	// Read the list of files to produce, which comes from the same places as
+	// the templates.
+	outputList, err := g.loadOutputList()
+	if err != nil {
+		return g.report, err
+	}
+
+	// Read the manifest of the files produced by the previous run, so that
+	// any that are not produced this time can be removed.
+	g.previousManifest, err = g.readManifest()
+	if err != nil {
+		return g.report, err
+	}
+
+	// Build the project from the templates and the spec.
+	err = g.generate(spec, outputList)
+	if err != nil {
+		return g.report, err
+	}
+
+	// Remove the files left over from the previous run and record what was
+	// produced by this one.
+	g.removeOrphans()
+	if g.TypeCheck {
+		g.typeCheck(spec.SourceBase)
+	}
+	if !g.DryRun && !g.Diff {
+		err = g.writeManifest()
+		if err != nil {
+			return g.report, fmt.Errorf("cannot write manifest %s - %s", manifestName,
+				err.Error())
+		}
+	}
+	return g.report, nil
+}
+
+// logf logs a detail if the options ask for it.
+func (g *generator) logf(format string, args ...interface{}) {
+	if g.Log != nil {
+		g.Log.Printf(format, args...)
+	}
+}
+
+// warn adds a warning to the report.
+func (g *generator) warn(format string, args ...interface{}) {
+	g.report.Warnings = append(g.report.Warnings, fmt.Sprintf(format, args...))
+}
+
+// createFileFromTemplate executes the template using the data (a Spec or a
+// Resource) and writes the result to the target file.  If the file exists and
+// is being overwritten, the contents of its protected regions are spliced
+// into the new version.  What happens to the file (or would happen in dry run
+// mode) is added to the report.
+func (g *generator) createFileFromTemplate(targetPathName string,
+	templateName string, data interface{}, overwrite bool) error {
+
+	g.logf("creating file %s from template %s", targetPathName, templateName)
+
+	// Produce the new contents before touching the existing file.
+	var buffer bytes.Buffer
+	err := g.templates[templateName].Execute(&buffer, data)
+	if err != nil {
+		return fmt.Errorf("error creating file %s from template %s - %s ",
+			targetPathName, templateName, err.Error())
+	}
+	content := buffer.Bytes()
+
+	oldContent, err := g.FS.ReadFile(targetPathName)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot read %s - %s", targetPathName, err.Error())
+	}
+
+	// If the file exists and is going to be overwritten, preserve its
+	// protected regions.
+	if exists && overwrite {
+		regions, _, err := parseProtectedRegions(oldContent, targetPathName)
+		if err != nil {
+			return err
+		}
+		content, err = g.spliceProtectedRegions(content, regions, targetPathName)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Format a Go file.  If that fails, the errors are only reported if the
+	// file is going to be produced.
+	var syntaxErrors Problems
+	if filepath.Ext(targetPathName) == ".go" {
+		content, syntaxErrors = formatGo(filepath.Clean(targetPathName), content)
+	}
+
+	file := FileReport{Path: filepath.Clean(targetPathName), Template: templateName}
+	switch {
+	case !exists:
+		file.Action = ActionCreate
+	case !overwrite:
+		file.Action = ActionSkip
+	case bytes.Equal(oldContent, content):
+		file.Action = ActionSame
+	default:
+		file.Action = ActionOverwrite
+	}
+
+	if file.Action == ActionSkip {
+		g.logf("file %s already exists and overwrite mode is off.", targetPathName)
+		g.recordSkippedFile(targetPathName, templateName)
+	} else {
+		g.recordGeneratedFile(targetPathName, templateName, content)
+		g.contents[file.Path] = content
+		g.report.Problems = append(g.report.Problems, syntaxErrors...)
+	}
+
+	// The diff names the file relative to the project directory, in the same
+	// form as "git diff", so it can be applied there with "git apply" or
+	// "patch -p1".
+	if g.Diff && (file.Action == ActionCreate || file.Action == ActionOverwrite) {
+		path := g.manifestPath(targetPathName)
+		oldName := "a/" + path
+		if !exists {
+			oldName = "/dev/null"
+		}
+		file.Diff = unifiedDiff(oldName, "b/"+path, oldContent, content)
+	}
+	g.report.Files = append(g.report.Files, file)
+
+	if g.DryRun || g.Diff || file.Action == ActionSkip {
+		return nil
+	}
+
+	g.logf("Creating file %s - overwrite %v.", targetPathName, overwrite)
+	err = g.FS.WriteFile(targetPathName, content, 0666)
+	if err != nil {
+		return fmt.Errorf("error writing file %s - %s ", targetPathName, err.Error())
+	}
+	return nil
+}
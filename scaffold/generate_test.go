@@ -0,0 +1,408 @@
+package scaffold
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// testSpec is a small spec with one resource, used by the tests that run
+// Generate.
+const testSpec = `{
+    "name": "animals",
+    "sourcebase": "github.com/goblimey/animals",
+    "db": "mysql",
+    "dbuser": "webuser",
+    "dbpassword": "secret",
+    "dbserver": "localhost",
+    "orm": "gorp",
+    "resources": [
+        {
+            "name": "cat",
+            "fields": [
+                {"name": "name", "type": "string", "mandatory": true},
+                {"name": "age", "type": "int"}
+            ]
+        }
+    ]
+}`
+
+// loadTestSpec writes the spec text to scaffold.json in the file system,
+// then loads and enhances it.
+func loadTestSpec(t *testing.T, fsys FS, text string) Spec {
+	t.Helper()
+	err := fsys.WriteFile("scaffold.json", []byte(text), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, problems, err := LoadSpec(fsys, "scaffold.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems.Errors() > 0 {
+		t.Fatalf("problems with the spec:\n%s", problems.Error())
+	}
+	err = Enhance(&spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+// generateTestProject generates the project for the spec text in a new
+// MemFS and returns the file system and the report.
+func generateTestProject(t *testing.T, text string, options Options) (*MemFS, Report) {
+	t.Helper()
+	fsys := &MemFS{}
+	spec := loadTestSpec(t, fsys, text)
+	options.FS = fsys
+	report, err := Generate(spec, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fsys, report
+}
+
+func TestGenerateProducesFiles(t *testing.T) {
+	fsys, report := generateTestProject(t, testSpec, Options{})
+
+	var testData = []struct {
+		path     string
+		template string
+	}{
+		{"go.mod", "go.mod.template"},
+		{"install.sh", "script.install.sh.template"},
+		{"animals.go", "main.go.template"},
+		{"hooks/cat/hooks.go", "hooks.go.template"},
+		{"generated/crud/models/cat/concrete_cat.go", "model.concrete.go.template"},
+		{"generated/crud/controllers/cat/controller.go", "controller.go.template"},
+		{"views/generated/crud/templates/cat/index.ghtml", "view.resource.index.ghtml.template"},
+		{"generated/sql/create.db.sql", "sql.create.db.template"},
+		{manifestName, ""},
+	}
+
+	for _, td := range testData {
+		if !fileExists(fsys, td.path) {
+			t.Errorf("%s was not produced", td.path)
+			continue
+		}
+		if td.template == "" {
+			continue
+		}
+		found := false
+		for _, file := range report.Files {
+			if file.Path == td.path {
+				found = true
+				if file.Action != ActionCreate || file.Template != td.template {
+					t.Errorf("%s: want create from %s, got %s from %s",
+						td.path, td.template, file.Action, file.Template)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s is not in the report", td.path)
+		}
+	}
+
+	// Every file in the report is in the file system, and the only other
+	// files are the spec and the manifest.
+	if len(fsys.Files()) != len(report.Files)+2 {
+		t.Errorf("want %d files, got %d", len(report.Files)+2, len(fsys.Files()))
+	}
+
+	info, err := fsys.Stat("install.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("install.sh: want mode 0700, got %o", info.Mode().Perm())
+	}
+}
+
+func TestGenerateAgain(t *testing.T) {
+	var testData = []struct {
+		description string
+		overwrite   bool
+		want        map[string]bool // the actions allowed on the second run
+	}{
+		{"normal", false, map[string]bool{ActionSame: true, ActionSkip: true}},
+		{"overwrite", true, map[string]bool{ActionSame: true}},
+	}
+
+	for _, td := range testData {
+		fsys, first := generateTestProject(t, testSpec, Options{})
+		spec := loadTestSpec(t, fsys, testSpec)
+		second, err := Generate(spec, Options{FS: fsys, Overwrite: td.overwrite})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(second.Files) != len(first.Files) {
+			t.Errorf("%s: want %d files, got %d", td.description,
+				len(first.Files), len(second.Files))
+		}
+		for _, file := range second.Files {
+			if !td.want[file.Action] {
+				t.Errorf("%s: %s - unexpected action %s", td.description, file.Path, file.Action)
+			}
+		}
+	}
+}
+
+func TestGenerateGoMod(t *testing.T) {
+	fsys, _ := generateTestProject(t, testSpec, Options{})
+	data, err := fsys.ReadFile("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The module path comes from the spec's sourcebase.
+	if !strings.Contains(string(data), "\nmodule github.com/goblimey/animals\n") {
+		t.Errorf("go.mod does not declare the module github.com/goblimey/animals:\n%s", data)
+	}
+	if !strings.Contains(string(data), "\tgopkg.in/gorp.v2 ") {
+		t.Errorf("go.mod does not require gorp:\n%s", data)
+	}
+
+	// go.mod belongs to the user once it's created, so an edited copy is
+	// kept unless the scaffolder is told to overwrite.
+	var testData = []struct {
+		description string
+		overwrite   bool
+		action      string
+		want        string
+	}{
+		{"normal", false, ActionSkip, "module github.com/goblimey/zoo\n"},
+		{"overwrite", true, ActionOverwrite, string(data)},
+	}
+
+	for _, td := range testData {
+		err := fsys.WriteFile("go.mod", []byte("module github.com/goblimey/zoo\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec := loadTestSpec(t, fsys, testSpec)
+		report, err := Generate(spec, Options{FS: fsys, Overwrite: td.overwrite})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range report.Files {
+			if file.Path == "go.mod" && file.Action != td.action {
+				t.Errorf("%s: want %s, got %s", td.description, td.action, file.Action)
+			}
+		}
+		got, err := fsys.ReadFile("go.mod")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != td.want {
+			t.Errorf("%s: want go.mod\n%s\ngot\n%s", td.description, td.want, got)
+		}
+	}
+
+	// A deleted go.mod is created again.
+	err = fsys.Remove("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := loadTestSpec(t, fsys, testSpec)
+	_, err = Generate(spec, Options{FS: fsys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fsys.ReadFile("go.mod")
+	if err != nil || string(got) != string(data) {
+		t.Errorf("go.mod was not created again - %v\n%s", err, got)
+	}
+}
+
+func TestGenerateColumn(t *testing.T) {
+	spec := strings.Replace(testSpec, `{"name": "age", "type": "int"}`,
+		`{"name": "age", "type": "int", "column": "age_in_years"}`, 1)
+	fsys, _ := generateTestProject(t, spec, Options{})
+
+	var testData = []struct {
+		path string
+		want []string
+	}{
+		{"generated/crud/repositories/cat/gorpmysql/concrete_repository.go", []string{
+			`table.ColMap("AgeField").Rename("age_in_years")`,
+			`"select id, name, age_in_years from cats"`,
+			`"select id, name, age_in_years from cats where id = ?"`}},
+		{"generated/crud/models/cat/gorp/concrete_cat.go", []string{
+			`db: "age_in_years"`}},
+	}
+
+	for _, td := range testData {
+		content, err := fsys.ReadFile(td.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range td.want {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s does not contain %s", td.path, want)
+			}
+		}
+	}
+}
+
+func TestGenerateDryRun(t *testing.T) {
+	var testData = []struct {
+		description string
+		options     Options
+		wantDiff    bool
+	}{
+		{"dry run", Options{DryRun: true}, false},
+		{"diff", Options{Diff: true}, true},
+	}
+
+	for _, td := range testData {
+		fsys, report := generateTestProject(t, testSpec, td.options)
+		if files := fsys.Files(); len(files) != 1 || files[0] != "scaffold.json" {
+			t.Errorf("%s: want only the spec, got %v", td.description, files)
+		}
+		if len(report.Files) == 0 {
+			t.Errorf("%s: the report is empty", td.description)
+		}
+		for _, file := range report.Files {
+			if file.Action != ActionCreate {
+				t.Errorf("%s: %s - want create, got %s", td.description, file.Path, file.Action)
+			}
+			if (file.Diff != "") != td.wantDiff {
+				t.Errorf("%s: %s - want diff %v, got %q", td.description, file.Path,
+					td.wantDiff, file.Diff)
+			}
+		}
+	}
+}
+
+func TestGenerateKeepsProtectedRegion(t *testing.T) {
+	const formFile = "generated/crud/forms/cat/concrete_single_item_form.go"
+	const custom = "// scaffolder:begin custom\nvar answer = 42\n\n// scaffolder:end\n"
+
+	fsys, _ := generateTestProject(t, testSpec, Options{})
+	content, err := fsys.ReadFile(formFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := bytes.Index(content, []byte("// scaffolder:begin custom"))
+	end := bytes.Index(content[start+1:], []byte("// scaffolder:end\n")) + start + 1
+	if start < 0 || end <= start {
+		t.Fatalf("%s has no custom region", formFile)
+	}
+	edited := string(content[:start]) + custom + string(content[end+len("// scaffolder:end\n"):])
+	err = fsys.WriteFile(formFile, []byte(edited), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := loadTestSpec(t, fsys, testSpec)
+	report, err := Generate(spec, Options{FS: fsys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err = fsys.ReadFile(formFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), custom) {
+		t.Errorf("the custom region was lost:\n%s", content)
+	}
+	for _, file := range report.Files {
+		if file.Path == formFile && file.Action != ActionSame {
+			t.Errorf("%s: want same, got %s", formFile, file.Action)
+		}
+	}
+}
+
+func TestGenerateNeedsEnhancedSpec(t *testing.T) {
+	fsys := &MemFS{}
+	fsys.WriteFile("scaffold.json", []byte(testSpec), 0644)
+	spec, _, err := LoadSpec(fsys, "scaffold.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Generate(spec, Options{FS: fsys})
+	if err == nil {
+		t.Error("want an error for a spec that has not been enhanced")
+	}
+}
+
+func TestGenerateInProjectDir(t *testing.T) {
+	fsys, report := generateTestProject(t, testSpec, Options{ProjectDir: "proj"})
+	for _, file := range report.Files {
+		if !strings.HasPrefix(file.Path, "proj/") || strings.HasPrefix(file.Path, "proj/proj/") {
+			t.Errorf("%s is not in the project directory", file.Path)
+		}
+	}
+	if !fileExists(fsys, "proj/go.mod") || !fileExists(fsys, "proj/"+manifestName) {
+		t.Errorf("the project is not in proj - got %v", fsys.Files())
+	}
+}
+
+func TestGenerateTypeChecks(t *testing.T) {
+	var testData = []struct {
+		description string
+		fields      string
+	}{
+		{"mandatory string", `{"name": "name", "type": "string", "mandatory": true}, {"name": "age", "type": "int"}`},
+		{"optional strings", `{"name": "name", "type": "string"}, {"name": "owner", "type": "string"}`},
+		{"no strings", `{"name": "age", "type": "int", "mandatory": true}, {"name": "weight", "type": "float"}`},
+	}
+
+	for _, td := range testData {
+		spec := strings.Replace(testSpec,
+			`{"name": "name", "type": "string", "mandatory": true},
+                {"name": "age", "type": "int"}`, td.fields, 1)
+		if spec == testSpec && td.description != "mandatory string" {
+			t.Fatalf("%s: the fields were not replaced", td.description)
+		}
+		_, report := generateTestProject(t, spec, Options{TypeCheck: true})
+		for _, problem := range report.Problems {
+			t.Errorf("%s: %s", td.description, problem.String())
+		}
+	}
+}
+
+func TestGenerateTypeCheckProblems(t *testing.T) {
+	// A template pack whose code parses but doesn't type-check.  It also
+	// imports a package that the checker can't see, which is taken on trust,
+	// and has a test in the same package, so the package is checked twice.
+	fsys := writeFiles(t, map[string]string{
+		ProjectTemplateDir + "/" + OutputListName: `{"outputs": [
+    {"template": "broken.go.template", "scope": "spec", "path": "broken/broken.go", "overwrite": "always",
+        "imports": ["github.com/example/missing"]},
+    {"template": "broken_test.go.template", "scope": "spec", "path": "broken/broken_test.go", "overwrite": "always"}
+]}`,
+		ProjectTemplateDir + "/broken.go.template": `package broken
+
+{{.Imports}}
+
+// Age is not an int.
+var Age int = "s"
+
+// Value comes from a package that is not checked.
+var Value = missing.Value
+`,
+		ProjectTemplateDir + "/broken_test.go.template": `package broken
+
+import "testing"
+
+func TestAge(t *testing.T) {
+	t.Log(Age)
+}
+`,
+	})
+	spec := loadTestSpec(t, fsys, testSpec)
+	report, err := Generate(spec, Options{FS: fsys, TypeCheck: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `broken/broken.go:8:15: cannot use "s" (untyped string constant) as int value in variable declaration`
+	got := make([]string, 0, len(report.Problems))
+	for _, problem := range report.Problems {
+		got = append(got, problem.String())
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("want the problem\n%s\ngot\n%s", want, strings.Join(got, "\n"))
+	}
+}
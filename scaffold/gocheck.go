@@ -0,0 +1,260 @@
+package scaffold
+
+import (
+	"errors"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Each generated Go file is run through go/format before it's written, so
+// the project is tidy even if the user never runs gofmt.  A file that won't
+// format has a syntax error, which is a bug in its template.  The file is
+// written as it is, so that the user can see what went wrong, and each error
+// is added to the report as a Problem with its position in the generated
+// file:
+//
+//	generated/crud/models/cat/concrete.go:42:3: expected ';', found 'if'
+//
+// If the TypeCheck option is set, the generated packages are also checked
+// with go/types once everything has been produced, which finds the mistakes
+// that only show up when the project is built, such as a misspelt method or
+// an unused variable.  The packages in the project are checked from their
+// source, using the new versions of the generated files (so it works in dry
+// run mode too) and the existing versions of the files that were left alone.
+// The standard library is checked against its export data.  Anything else,
+// such as gorp or the mocks that test.sh generates, is not checked, and the
+// code that uses it is taken on trust.
+
+// errNotChecked is returned by the importer for a package that is not checked.
+var errNotChecked = errors.New("not checked")
+
+// formatGo formats a generated Go file.  If it can't be formatted, it returns
+// the content unchanged and the syntax errors.
+func formatGo(pathName string, content []byte) ([]byte, Problems) {
+	formatted, err := format.Source(content)
+	if err == nil {
+		// An indented doc comment is moved by the first pass and tidied by
+		// the second, so format again to give what gofmt would leave alone.
+		if again, err := format.Source(formatted); err == nil {
+			formatted = again
+		}
+		return formatted, nil
+	}
+	var syntaxErrors scanner.ErrorList
+	if !errors.As(err, &syntaxErrors) {
+		return content, Problems{{Position: pathName, Message: err.Error()}}
+	}
+	problems := make(Problems, 0, len(syntaxErrors))
+	for _, syntaxError := range syntaxErrors {
+		position := syntaxError.Pos
+		position.Filename = pathName
+		problems = append(problems,
+			Problem{Position: position.String(), Message: syntaxError.Msg})
+	}
+	return content, problems
+}
+
+// typeChecker checks the Go packages in the project.  It's also the importer
+// used by go/types, so a project package is checked the first time that it's
+// imported.
+type typeChecker struct {
+	g          *generator
+	sourceBase string
+	fileSet    *token.FileSet
+	std        types.Importer
+	removed    map[string]bool           // files that this run removed, or would remove
+	packages   map[string]*types.Package // the project packages checked so far
+	checking   map[string]bool           // to spot an import cycle
+	reported   map[string]bool           // each problem is only reported once
+}
+
+// typeCheck checks each directory that contains a generated Go file, along
+// with its tests, and adds the errors to the report.
+func (g *generator) typeCheck(sourceBase string) {
+	c := typeChecker{g: g, sourceBase: sourceBase, fileSet: token.NewFileSet(),
+		std:      importer.Default(),
+		removed:  make(map[string]bool),
+		packages: make(map[string]*types.Package),
+		checking: make(map[string]bool),
+		reported: make(map[string]bool)}
+
+	dirs := make(map[string]bool)
+	for _, file := range g.report.Files {
+		switch {
+		case file.Action == ActionRemove:
+			c.removed[file.Path] = true
+		case filepath.Ext(file.Path) == ".go":
+			dirs[filepath.Dir(file.Path)] = true
+		}
+	}
+	sortedDirs := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sortedDirs = append(sortedDirs, dir)
+	}
+	sort.Strings(sortedDirs)
+
+	for _, dir := range sortedDirs {
+		c.checkDir(dir)
+	}
+}
+
+// checkDir checks the package in a directory, then the package with its
+// tests, then any tests in a separate _test package.
+func (c *typeChecker) checkDir(dir string) {
+	importPath, ok := c.importPath(dir)
+	if !ok {
+		return
+	}
+	c.g.logf("type checking %s", importPath)
+	files, tests, externalTests := c.parseDir(dir)
+	if len(files) > 0 {
+		c.importPackage(importPath)
+	}
+	if len(tests) > 0 {
+		c.check(importPath, append(append([]*ast.File{}, files...), tests...))
+	}
+	if len(externalTests) > 0 {
+		c.check(importPath+"_test", externalTests)
+	}
+}
+
+// Import implements types.Importer.  A package in the project is checked from
+// its source, the standard library comes from its export data, and anything
+// else is not checked.
+func (c *typeChecker) Import(path string) (*types.Package, error) {
+	if path == c.sourceBase || strings.HasPrefix(path, c.sourceBase+"/") {
+		return c.importPackage(path)
+	}
+	if !strings.Contains(strings.Split(path, "/")[0], ".") {
+		return c.std.Import(path)
+	}
+	return nil, errNotChecked
+}
+
+// importPackage checks a package in the project, if it hasn't already been
+// checked, and returns it.
+func (c *typeChecker) importPackage(importPath string) (*types.Package, error) {
+	if pkg, ok := c.packages[importPath]; ok {
+		return pkg, nil
+	}
+	if c.checking[importPath] {
+		return nil, errors.New("import cycle")
+	}
+	dir := filepath.Join(c.g.ProjectDir,
+		filepath.FromSlash(strings.TrimPrefix(importPath, c.sourceBase)))
+	files, _, _ := c.parseDir(dir)
+	if len(files) == 0 {
+		// For example the mocks, which don't exist until test.sh is run.
+		return nil, errNotChecked
+	}
+	c.checking[importPath] = true
+	pkg := c.check(importPath, files)
+	delete(c.checking, importPath)
+	c.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// check type-checks one package and adds its errors to the report.
+func (c *typeChecker) check(importPath string, files []*ast.File) *types.Package {
+	config := types.Config{Importer: c, Error: func(err error) {
+		typeError, ok := err.(types.Error)
+		if !ok {
+			c.report(Problem{Message: err.Error()})
+			return
+		}
+		if strings.Contains(typeError.Msg, errNotChecked.Error()) {
+			return
+		}
+		c.report(Problem{Position: c.fileSet.Position(typeError.Pos).String(),
+			Message: typeError.Msg})
+	}}
+	pkg, _ := config.Check(importPath, c.fileSet, files, nil)
+	return pkg
+}
+
+// report adds a problem to the report, unless it's already there.  A file
+// that is checked as part of a package and again along with its tests gives
+// the same errors twice.
+func (c *typeChecker) report(problem Problem) {
+	key := problem.String()
+	if !c.reported[key] {
+		c.reported[key] = true
+		c.g.report.Problems = append(c.g.report.Problems, problem)
+	}
+}
+
+// importPath returns the import path of a directory in the project.
+func (c *typeChecker) importPath(dir string) (string, bool) {
+	relative, err := filepath.Rel(c.g.ProjectDir, dir)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if relative == "." {
+		return c.sourceBase, true
+	}
+	return c.sourceBase + "/" + filepath.ToSlash(relative), true
+}
+
+// parseDir parses the Go files in a directory and returns the files of the
+// package, the tests in the same package and the tests in a separate _test
+// package.  A file with a syntax error is left out.  If it was generated, the
+// error has already been reported by formatGo.
+func (c *typeChecker) parseDir(dir string) ([]*ast.File, []*ast.File, []*ast.File) {
+	names := make(map[string]bool)
+	for name := range c.g.contents {
+		if filepath.Dir(name) == dir && filepath.Ext(name) == ".go" {
+			names[name] = true
+		}
+	}
+	entries, _ := c.g.FS.ReadDir(dir)
+	for _, entry := range entries {
+		name := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() && filepath.Ext(name) == ".go" && !c.removed[name] {
+			names[name] = true
+		}
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	var files, tests, externalTests []*ast.File
+	packageName := ""
+	for _, name := range sortedNames {
+		content, ok := c.g.contents[name]
+		if !ok {
+			var err error
+			content, err = c.g.FS.ReadFile(name)
+			if err != nil {
+				continue
+			}
+		}
+		file, err := parser.ParseFile(c.fileSet, name, content, 0)
+		if err != nil {
+			continue
+		}
+		switch {
+		case !strings.HasSuffix(name, "_test.go"):
+			files = append(files, file)
+			packageName = file.Name.Name
+		case strings.HasSuffix(file.Name.Name, "_test"):
+			externalTests = append(externalTests, file)
+		default:
+			tests = append(tests, file)
+		}
+	}
+	if packageName == "" && len(tests) > 0 {
+		// Tests with nothing to test are a package of their own.
+		files, tests = tests, nil
+	}
+	return files, tests, externalTests
+}
@@ -0,0 +1,171 @@
+package scaffold
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A large spec can be split into several files, for example one for each
+// resource, so that different people can look after different resources.
+// The main spec lists the files to include:
+//
+//	"include": ["resources/*.json", "legacy.yaml"],
+//
+// Each entry is a file name or a glob pattern, relative to the directory
+// containing the main spec.  An included file can be in any of the formats
+// that the main spec can, but it can only contain resources and inflections,
+// which are added to those of the main spec.  It can't include other files.
+// If an included resource has the same name as one that's already defined,
+// that's an error.
+
+// A resourceOrigin says where a resource in the merged spec came from: its
+// source (nil for the main spec) and its index in that file.
+type resourceOrigin struct {
+	source *specSource
+	index  int
+}
+
+// resourceOrigins holds the origin of each resource in the merged spec.
+type resourceOrigins []resourceOrigin
+
+// resourcePath matches the path of an item within a resource.
+var resourcePath = regexp.MustCompile(`^resources\[([0-9]+)\](.*)$`)
+
+// move checks whether a problem with the merged spec is in a resource from an
+// included file.  If so, it changes the path of the problem to the one in
+// that file and returns the file, otherwise it returns nil.
+func (o resourceOrigins) move(problem *Problem) *specSource {
+	match := resourcePath.FindStringSubmatch(problem.path)
+	if match == nil {
+		return nil
+	}
+	i, _ := strconv.Atoi(match[1])
+	if i >= len(o) || o[i].source == nil {
+		return nil
+	}
+	problem.path = fmt.Sprintf("resources[%d]%s", o[i].index, match[2])
+	problem.offset = -1
+	return o[i].source
+}
+
+// fragmentKeys are the settings that an included file can contain.
+var fragmentKeys = wordSet(`$schema resources inflections`)
+
+// includeFragments reads the files that the main spec includes and adds their
+// resources and inflections to the spec.  It returns the origin of each
+// resource in the merged spec and the included files, each holding the
+// problems found in it so far.  Problems with the include list are added to
+// the main spec's source.  If an included file can't be parsed, its problems
+// are returned separately, with their positions set.
+func includeFragments(fsys FS, source *specSource, spec *Spec) (resourceOrigins, []*specSource, []Problem) {
+	origins := make(resourceOrigins, len(spec.Resources))
+	for i := range origins {
+		origins[i].index = i
+	}
+	if len(spec.Include) == 0 {
+		return origins, nil, nil
+	}
+
+	fragments := make([]*specSource, 0)
+	unparsed := make([]Problem, 0)
+
+	// definedIn gives the file that defines each resource, by lower case name.
+	definedIn := make(map[string]string)
+	for _, resource := range spec.Resources {
+		definedIn[strings.ToLower(resource.Name)] = source.file
+	}
+
+	// A file is only included once, and the main spec is never included.
+	included := make(map[string]bool)
+	if absolute, err := filepath.Abs(source.file); err == nil {
+		included[absolute] = true
+	}
+
+	dir := filepath.Dir(source.file)
+	for i, pattern := range spec.Include {
+		path := fmt.Sprintf("include[%d]", i)
+		fullPattern := pattern
+		if !filepath.IsAbs(fullPattern) {
+			fullPattern = filepath.Join(dir, pattern)
+		}
+		files, err := glob(fsys, fullPattern)
+		if err != nil {
+			source.problems = append(source.problems, Problem{path: path,
+				Message: fmt.Sprintf("include pattern %q is not valid - %s",
+					pattern, err.Error())})
+			continue
+		}
+		if len(files) == 0 {
+			if strings.ContainsAny(pattern, `*?[\`) {
+				source.problems = append(source.problems, Problem{path: path,
+					Message: fmt.Sprintf("include pattern %q doesn't match any files",
+						pattern),
+					Warning: true})
+			} else {
+				source.problems = append(source.problems, Problem{path: path,
+					Message: fmt.Sprintf("cannot find the included file %s", fullPattern)})
+			}
+			continue
+		}
+
+		for _, file := range files {
+			absolute, err := filepath.Abs(file)
+			if err == nil {
+				if included[absolute] {
+					continue
+				}
+				included[absolute] = true
+			}
+
+			data, err := fsys.ReadFile(file)
+			if err != nil {
+				source.problems = append(source.problems, Problem{path: path,
+					Message: fmt.Sprintf("cannot read the included file %s - %s",
+						file, err.Error())})
+				continue
+			}
+			fragmentSpec, fragment, problems := parseSpec(file, data)
+			if fragment == nil {
+				unparsed = append(unparsed, problems...)
+				continue
+			}
+			fragments = append(fragments, fragment)
+
+			for key := range fragment.positions {
+				if key != "" && !strings.ContainsAny(key, ".[") && !fragmentKeys[key] {
+					fragment.problems = append(fragment.problems, Problem{path: key,
+						Message: fmt.Sprintf("%q can't be set in an included file - only resources and inflections can",
+							key)})
+				}
+			}
+
+			if spec.Inflections.Irregular == nil {
+				spec.Inflections.Irregular = make(map[string]string)
+			}
+			for singular, plural := range fragmentSpec.Inflections.Irregular {
+				spec.Inflections.Irregular[singular] = plural
+			}
+			spec.Inflections.Uncountable = append(spec.Inflections.Uncountable,
+				fragmentSpec.Inflections.Uncountable...)
+
+			for j, resource := range fragmentSpec.Resources {
+				name := strings.ToLower(resource.Name)
+				if other, ok := definedIn[name]; ok && name != "" {
+					fragment.problems = append(fragment.problems, Problem{
+						path: fmt.Sprintf("resources[%d].name", j),
+						Message: fmt.Sprintf("resource %q is already defined in %s",
+							resource.Name, other)})
+					continue
+				}
+				definedIn[name] = file
+				spec.Resources = append(spec.Resources, resource)
+				origins = append(origins, resourceOrigin{fragment, j})
+			}
+		}
+	}
+
+	return origins, fragments, unparsed
+}
@@ -0,0 +1,124 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+// includingSpec is a main spec that includes other files.  %s is replaced by
+// the include list.
+const includingSpec = `{
+    "name": "animals",
+    "sourcebase": "github.com/goblimey/animals",
+    "db": "mysql",
+    "dbuser": "webuser",
+    "dbpassword": "secret",
+    "dbserver": "localhost",
+    "orm": "gorp",
+    "include": [%s],
+    "resources": [
+        {"name": "cat", "fields": [{"name": "name", "type": "string"}]}
+    ]
+}`
+
+// writeFiles writes each of the files to a new MemFS.
+func writeFiles(t *testing.T, files map[string]string) *MemFS {
+	t.Helper()
+	fsys := &MemFS{}
+	for name, text := range files {
+		err := fsys.WriteFile(name, []byte(text), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fsys
+}
+
+func TestInclude(t *testing.T) {
+	fsys := writeFiles(t, map[string]string{
+		"spec/scaffold.json": strings.Replace(includingSpec, "%s",
+			`"resources/*.json", "legacy.yaml", "scaffold.json"`, 1),
+		"spec/resources/dog.json": `{"resources": [{"name": "dog", "fields": [{"name": "age", "type": "int"}]}]}`,
+		"spec/resources/mouse.json": `{
+    "inflections": {"irregular": {"mouse": "mice"}},
+    "resources": [{"name": "mouse", "fields": [{"name": "name", "type": "string"}]}]
+}`,
+		"spec/legacy.yaml": "inflections:\n  uncountable: [sheep]\nresources:\n  - name: sheep\n    fields:\n      - {name: name, type: string}\n",
+	})
+
+	spec, problems, err := LoadSpec(fsys, "spec/scaffold.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems.Errors() > 0 {
+		t.Fatalf("problems with the spec:\n%s", problems.Error())
+	}
+	names := make([]string, 0)
+	for _, resource := range spec.Resources {
+		names = append(names, resource.Name)
+	}
+	if strings.Join(names, " ") != "cat dog mouse sheep" {
+		t.Errorf("want the resources cat dog mouse sheep, got %v", names)
+	}
+
+	err = Enhance(&spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, resource := range spec.Resources {
+		want := map[string]string{"cat": "cats", "dog": "dogs", "mouse": "mice", "sheep": "sheep"}
+		if resource.PluralName != want[resource.Name] {
+			t.Errorf("%s: want the plural %s, got %s", resource.Name, want[resource.Name],
+				resource.PluralName)
+		}
+	}
+}
+
+func TestIncludeProblems(t *testing.T) {
+	var testData = []struct {
+		description string
+		include     string
+		files       map[string]string
+		want        string
+	}{
+		{"missing file", `"dog.json"`, nil,
+			"scaffold.json:9:17: cannot find the included file dog.json"},
+		{"no match", `"resources/*.json"`, nil,
+			`scaffold.json:9:17: warning: include pattern "resources/*.json" doesn't match any files`},
+		{"bad pattern", `"[.json"`, nil,
+			`scaffold.json:9:17: include pattern "[.json" is not valid`},
+		{"problem in the included file", `"dog.yaml"`,
+			map[string]string{"dog.yaml": "resources:\n  - name: dog\n    fields:\n      - {name: age, type: integr}\n"},
+			`dog.yaml:4:21: field "age" has type "integr"`},
+		{"syntax error in the included file", `"dog.json"`,
+			map[string]string{"dog.json": `{"resources": [}`},
+			"dog.json:1:"},
+		{"defined twice", `"cat.json"`,
+			map[string]string{"cat.json": `{"resources": [{"name": "Cat", "fields": [{"name": "age", "type": "int"}]}]}`},
+			`cat.json:1:17: resource "Cat" is already defined in scaffold.json`},
+		{"not allowed", `"dog.json"`,
+			map[string]string{"dog.json": `{"name": "dogs", "resources": [{"name": "dog", "fields": [{"name": "age", "type": "int"}]}]}`},
+			`dog.json:1:2: "name" can't be set in an included file - only resources and inflections can`},
+	}
+
+	for _, td := range testData {
+		files := map[string]string{"scaffold.json": strings.Replace(includingSpec, "%s", td.include, 1)}
+		for name, text := range td.files {
+			files[name] = text
+		}
+		fsys := writeFiles(t, files)
+		_, problems, err := LoadSpec(fsys, "scaffold.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, problem := range problems {
+			if strings.HasPrefix(problem.String(), td.want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: want %s, got\n%s", td.description, td.want, problems.Error())
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package scaffold
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// The inflection engine produces the plural of a singular English noun and
+// vice versa, so that the spec only needs to give a resource's plural name
+// when the rules get it wrong.  It works in the same way as the Rails
+// inflector: a word is first looked up in the list of uncountable words,
+// which are the same in both forms, then in the list of irregular words, then
+// the rules are tried, newest first.  The spec can add its own words:
+//
+//	"inflections": {
+//	    "irregular": {"cactus": "cacti"},
+//	    "uncountable": ["furniture"]
+//	}
+//
+// Only the last word of a name is changed, so "BlackSheep" is unchanged and
+// "CatAndMouse" => "CatAndMice".  The case of the part of the word that's
+// kept is kept too, so "URL" => "URLs".
+//
+// The built-in lists are never changed.  The words from a spec are held in
+// an inflector, which is only used for that spec, so one spec's words can't
+// leak into another and specs can be enhanced and generated concurrently.
+
+// Inflections holds the extra words given in the spec.  Irregular maps the
+// singular to the plural.
+type Inflections struct {
+	Irregular   map[string]string `json:"irregular"`
+	Uncountable []string          `json:"uncountable"`
+}
+
+// inflectionRule replaces the part of a word matching the pattern.
+type inflectionRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// The rules are tried from last to first, so the more specific rules come
+// later.
+var pluralRules = makeInflectionRules([][2]string{
+	{`$`, "s"},
+	{`s$`, "s"},
+	{`(ax|test)is$`, "${1}es"},
+	{`(octop|vir)us$`, "${1}i"},
+	{`(alias|status|campus|bus)$`, "${1}es"},
+	{`(buffal|tomat|potat|her|ech)o$`, "${1}oes"},
+	{`([ti])um$`, "${1}a"},
+	{`sis$`, "ses"},
+	{`(?:([^f])fe|([lr])f)$`, "${1}${2}ves"},
+	{`(hive)$`, "${1}s"},
+	{`([^aeiouy]|qu)y$`, "${1}ies"},
+	{`(x|ch|ss|sh|z)$`, "${1}es"},
+	{`(matr|vert|ind)(?:ix|ex)$`, "${1}ices"},
+	{`([ml])ouse$`, "${1}ice"},
+	{`^(ox)$`, "${1}en"},
+	{`(quiz)$`, "${1}zes"},
+})
+
+var singularRules = makeInflectionRules([][2]string{
+	{`s$`, ""},
+	{`(ss)$`, "${1}"},
+	{`([ti])a$`, "${1}um"},
+	{`((a)naly|(b)a|(d)iagno|(p)arenthe|(p)rogno|(s)ynop|(t)he)ses$`, "${1}sis"},
+	{`(^analy)ses$`, "${1}sis"},
+	{`([^f])ves$`, "${1}fe"},
+	{`(hive)s$`, "${1}"},
+	{`(tive)s$`, "${1}"},
+	{`([lr])ves$`, "${1}f"},
+	{`([^aeiouy]|qu)ies$`, "${1}y"},
+	{`(x|ch|ss|sh|z)es$`, "${1}"},
+	{`([ml])ice$`, "${1}ouse"},
+	{`(bus|campus)es$`, "${1}"},
+	{`(o)es$`, "${1}"},
+	{`(shoe)s$`, "${1}"},
+	{`(cris|ax|test)es$`, "${1}is"},
+	{`(octop|vir)i$`, "${1}us"},
+	{`(alias|status)es$`, "${1}"},
+	{`^(ox)en`, "${1}"},
+	{`(vert|ind)ices$`, "${1}ex"},
+	{`(matr)ices$`, "${1}ix"},
+	{`(quiz)zes$`, "${1}"},
+})
+
+// irregularPlurals maps the singular of an irregular word to its plural.
+var irregularPlurals = map[string]string{
+	"person":     "people",
+	"man":        "men",
+	"woman":      "women",
+	"child":      "children",
+	"foot":       "feet",
+	"tooth":      "teeth",
+	"goose":      "geese",
+	"criterion":  "criteria",
+	"phenomenon": "phenomena",
+	"cactus":     "cacti",
+	"leaf":       "leaves",
+	"loaf":       "loaves",
+	"thief":      "thieves",
+	"move":       "moves",
+	"zombie":     "zombies",
+
+	// Words ending in "ie", which the singular rules would turn into "y".
+	"movie":    "movies",
+	"cookie":   "cookies",
+	"pie":      "pies",
+	"tie":      "ties",
+	"lie":      "lies",
+	"calorie":  "calories",
+	"brownie":  "brownies",
+	"rookie":   "rookies",
+	"hippie":   "hippies",
+	"genie":    "genies",
+	"prairie":  "prairies",
+	"sortie":   "sorties",
+	"smoothie": "smoothies",
+	"selfie":   "selfies",
+	"goalie":   "goalies",
+	"birdie":   "birdies",
+	"auntie":   "aunties",
+	"freebie":  "freebies",
+	"hoodie":   "hoodies",
+	"newbie":   "newbies",
+}
+
+// uncountableWords are the same in the singular and the plural.
+var uncountableWords = map[string]bool{
+	"equipment":   true,
+	"information": true,
+	"rice":        true,
+	"money":       true,
+	"species":     true,
+	"series":      true,
+	"fish":        true,
+	"sheep":       true,
+	"deer":        true,
+	"moose":       true,
+	"news":        true,
+	"police":      true,
+	"aircraft":    true,
+	"software":    true,
+	"hardware":    true,
+	"metadata":    true,
+	"data":        true,
+	"feedback":    true,
+}
+
+// makeInflectionRules compiles a list of (pattern, replacement) pairs.  The
+// patterns are matched against the lower case word.
+func makeInflectionRules(pairs [][2]string) []inflectionRule {
+	rules := make([]inflectionRule, len(pairs))
+	for i, pair := range pairs {
+		rules[i] = inflectionRule{regexp.MustCompile(pair[0]), pair[1]}
+	}
+	return rules
+}
+
+// An inflector produces plurals and singulars using the built-in words and
+// the extra words given in a spec, which take priority.  The zero value only
+// uses the built-in words.
+type inflector struct {
+	irregular   map[string]string // singular => plural, in lower case
+	uncountable map[string]bool
+}
+
+// newInflector returns an inflector for the words given in a spec.
+func newInflector(inflections Inflections) inflector {
+	in := inflector{irregular: make(map[string]string), uncountable: make(map[string]bool)}
+	for singular, plural := range inflections.Irregular {
+		in.irregular[strings.ToLower(singular)] = strings.ToLower(plural)
+	}
+	for _, word := range inflections.Uncountable {
+		in.uncountable[strings.ToLower(word)] = true
+	}
+	return in
+}
+
+// Plural returns the plural of a name: "cat" => "cats", "person" => "people".
+// It only uses the built-in words.
+func Plural(name string) string {
+	return inflector{}.plural(name)
+}
+
+// Singular returns the singular of a name: "cats" => "cat", "people" =>
+// "person".  It only uses the built-in words.
+func Singular(name string) string {
+	return inflector{}.singular(name)
+}
+
+// plural returns the plural of a name.
+func (in inflector) plural(name string) string {
+	return in.inflect(name, func(word string) string {
+		if plural, ok := in.irregular[word]; ok {
+			return plural
+		}
+		if plural, ok := irregularPlurals[word]; ok {
+			return plural
+		}
+		if _, ok := in.irregularSingular(word); ok {
+			return word
+		}
+		return applyInflectionRules(word, pluralRules)
+	})
+}
+
+// singular returns the singular of a name.
+func (in inflector) singular(name string) string {
+	return in.inflect(name, func(word string) string {
+		if singular, ok := in.irregularSingular(word); ok {
+			return singular
+		}
+		if _, ok := in.irregular[word]; ok {
+			return word
+		}
+		if _, ok := irregularPlurals[word]; ok {
+			return word
+		}
+		return applyInflectionRules(word, singularRules)
+	})
+}
+
+// irregularSingular returns the singular of an irregular plural.
+func (in inflector) irregularSingular(word string) (string, bool) {
+	for singular, plural := range in.irregular {
+		if word == plural {
+			return singular, true
+		}
+	}
+	for singular, plural := range irregularPlurals {
+		if word == plural {
+			return singular, true
+		}
+	}
+	return "", false
+}
+
+// inflect applies the function to the last word of the name, in lower case,
+// and puts the result back.  The part of the word that is the same in the
+// result keeps its case, so "Cats" => "Cat" and "URL" => "URLs".  If an
+// all capitals word changes in the middle, the whole result is in capitals,
+// so "PERSON" => "PEOPLE".
+func (in inflector) inflect(name string, f func(word string) string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	last := words[len(words)-1]
+	i := strings.LastIndex(name, last)
+	lower := strings.ToLower(last)
+	if uncountableWords[lower] || in.uncountable[lower] {
+		return name
+	}
+
+	result := f(lower)
+	lastRunes, resultRunes := []rune(last), []rune(result)
+	same := 0
+	for same < len(lastRunes) && same < len(resultRunes) &&
+		unicode.ToLower(lastRunes[same]) == resultRunes[same] {
+		same++
+	}
+	allCapitals := len(lastRunes) > 1 && last == strings.ToUpper(last)
+	if allCapitals && same < len(lastRunes) && same < len(resultRunes) {
+		result = strings.ToUpper(result)
+	} else {
+		result = string(lastRunes[:same]) + string(resultRunes[same:])
+		if same == 0 && unicode.IsUpper(lastRunes[0]) {
+			result = upperFirstRune(result)
+		}
+	}
+	return name[:i] + result + name[i+len(last):]
+}
+
+// applyInflectionRules applies the first matching rule, trying the newest
+// first.
+func applyInflectionRules(word string, rules []inflectionRule) string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].pattern.MatchString(word) {
+			return rules[i].pattern.ReplaceAllString(word, rules[i].replacement)
+		}
+	}
+	return word
+}
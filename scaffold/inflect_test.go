@@ -0,0 +1,163 @@
+package scaffold
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPluralAndSingular(t *testing.T) {
+	var testData = []struct {
+		singular string
+		plural   string
+	}{
+		{"cat", "cats"},
+		{"Cat", "Cats"},
+		{"mouse", "mice"},
+		{"person", "people"},
+		{"Person", "People"},
+		{"PERSON", "PEOPLE"},
+		{"box", "boxes"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"leaf", "leaves"},
+		{"knife", "knives"},
+		{"status", "statuses"},
+		{"analysis", "analyses"},
+		{"matrix", "matrices"},
+		{"potato", "potatoes"},
+		{"movie", "movies"},
+		{"cookie", "cookies"},
+		{"pie", "pies"},
+		{"zombie", "zombies"},
+		{"URL", "URLs"},
+		{"ID", "IDs"},
+		{"CatAndMouse", "CatAndMice"},
+		{"favouriteMovie", "favouriteMovies"},
+		{"cat_and_dog", "cat_and_dogs"},
+		{"sheep", "sheep"},
+		{"BlackSheep", "BlackSheep"},
+		{"information", "information"},
+	}
+
+	for _, td := range testData {
+		if got := Plural(td.singular); got != td.plural {
+			t.Errorf("Plural(%q) - want %q, got %q", td.singular, td.plural, got)
+		}
+		if got := Singular(td.plural); got != td.singular {
+			t.Errorf("Singular(%q) - want %q, got %q", td.plural, td.singular, got)
+		}
+	}
+}
+
+func TestPluralAndSingularUnchanged(t *testing.T) {
+	var testData = []struct {
+		name     string
+		plural   string
+		singular string
+	}{
+		{"cats", "cats", "cat"},
+		{"people", "people", "person"},
+		{"URLS", "URLS", "URL"},
+		{"", "", ""},
+	}
+
+	for _, td := range testData {
+		if got := Plural(td.name); got != td.plural {
+			t.Errorf("Plural(%q) - want %q, got %q", td.name, td.plural, got)
+		}
+		if got := Singular(td.name); got != td.singular {
+			t.Errorf("Singular(%q) - want %q, got %q", td.name, td.singular, got)
+		}
+	}
+}
+
+func TestInflectorUsesSpecWords(t *testing.T) {
+	in := newInflector(Inflections{
+		Irregular:   map[string]string{"Octopus": "octopodes"},
+		Uncountable: []string{"furniture"},
+	})
+
+	var testData = []struct {
+		f    func(string) string
+		name string
+		want string
+	}{
+		{in.plural, "octopus", "octopodes"},
+		{in.singular, "octopodes", "octopus"},
+		{in.plural, "furniture", "furniture"},
+		{in.plural, "cat", "cats"},
+		// The built-in words are not changed.
+		{Plural, "octopus", "octopi"},
+		{Plural, "furniture", "furnitures"},
+	}
+
+	for i, td := range testData {
+		if got := td.f(td.name); got != td.want {
+			t.Errorf("%d: %q - want %q, got %q", i, td.name, td.want, got)
+		}
+	}
+}
+
+// TestInflectionsDontLeak checks that the words given in one spec don't
+// affect another, even when they are enhanced at the same time.
+func TestInflectionsDontLeak(t *testing.T) {
+	const withWords = `{
+    "name": "animals",
+    "sourcebase": "github.com/goblimey/animals",
+    "db": "mysql",
+    "dbuser": "webuser",
+    "orm": "gorp",
+    "inflections": {"irregular": {"cat": "kittens"}},
+    "resources": [{"name": "cat", "fields": [{"name": "name", "type": "string"}]}]
+}`
+	const withoutWords = `{
+    "name": "animals",
+    "sourcebase": "github.com/goblimey/animals",
+    "db": "mysql",
+    "dbuser": "webuser",
+    "orm": "gorp",
+    "resources": [{"name": "cat", "fields": [{"name": "name", "type": "string"}]}]
+}`
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, td := range []struct{ text, want string }{
+			{withWords, "kittens"},
+			{withoutWords, "cats"},
+		} {
+			wg.Add(1)
+			go func(text string, want string) {
+				defer wg.Done()
+				fsys := &MemFS{}
+				fsys.WriteFile("scaffold.json", []byte(text), 0644)
+				spec, _, err := LoadSpec(fsys, "scaffold.json")
+				if err == nil {
+					err = Enhance(&spec)
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got := spec.Resources[0].PluralName; got != want {
+					t.Errorf("want plural %q, got %q", want, got)
+				}
+			}(td.text, td.want)
+		}
+	}
+	wg.Wait()
+}
+
+func TestTemplateFuncsUseSpecWords(t *testing.T) {
+	funcs := templateFuncsFor(Inflections{Irregular: map[string]string{"cat": "kittens"}})
+	plural := funcs["plural"].(func(string) string)
+	singular := funcs["singular"].(func(string) string)
+	if got := plural("Cat"); got != "Kittens" {
+		t.Errorf("plural - want Kittens, got %q", got)
+	}
+	if got := singular("kittens"); got != "cat" {
+		t.Errorf("singular - want cat, got %q", got)
+	}
+	if got := templateFuncs["plural"].(func(string) string)("cat"); got != "cats" {
+		t.Errorf("the built-in plural has changed - got %q", got)
+	}
+}
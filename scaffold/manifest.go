@@ -0,0 +1,187 @@
+package scaffold
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// The generation manifest records every file that the scaffolder produced,
+// the template that produced it and a checksum of its contents.  It's written
+// to the project directory at the end of each run.  On the next run, any file
+// in the old manifest that is not produced again (for example because a
+// resource has been removed from the specification) is an orphan.  Orphans
+// are deleted, unless they have been changed since they were generated, in
+// which case the user is warned and the file is left alone.  A file that
+// belongs to the user once it's created (an "ifmissing" output, such as the
+// hooks for a resource) is never removed.  The user is warned about it once
+// and it's dropped from the manifest.
+
+// manifestName is the name of the manifest file in the project directory.
+const manifestName = "scaffold.manifest.json"
+
+// ManifestEntry describes one generated file.  The path is relative to the
+// project directory.  CreateOnce is set for a file that belongs to the user
+// once it's created.
+type ManifestEntry struct {
+	Path       string `json:"path"`
+	Template   string `json:"template"`
+	Checksum   string `json:"checksum"`
+	CreateOnce bool   `json:"createOnce,omitempty"`
+}
+
+// Manifest is the list of files produced by a run of the scaffolder.
+type Manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// recordGeneratedFile adds a file to the manifest of this run.
+func (g *generator) recordGeneratedFile(targetPathName string, templateName string,
+	content []byte) {
+
+	path := g.manifestPath(targetPathName)
+	g.manifestEntries[path] = ManifestEntry{Path: path, Template: templateName,
+		Checksum: checksum(content), CreateOnce: g.createOnce[templateName]}
+}
+
+// recordSkippedFile adds a file that was left alone because it already
+// exists to the manifest of this run.  The entry from the previous manifest
+// is carried forward so that the file is not treated as an orphan.  If there
+// is no previous entry, the file was not produced by the scaffolder as far as
+// the manifest is concerned, so it's not recorded.
+func (g *generator) recordSkippedFile(targetPathName string, templateName string) {
+	path := g.manifestPath(targetPathName)
+	for _, entry := range g.previousManifest.Files {
+		if entry.Path == path {
+			entry.CreateOnce = g.createOnce[templateName]
+			g.manifestEntries[path] = entry
+			return
+		}
+	}
+}
+
+// readManifest reads the manifest left by the previous run.  If there isn't
+// one, the result is empty.
+func (g *generator) readManifest() (Manifest, error) {
+	var manifest Manifest
+	data, err := g.FS.ReadFile(filepath.Join(g.ProjectDir, manifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return manifest, err
+	}
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("cannot read manifest %s - %s",
+			manifestName, err.Error())
+	}
+	return manifest, nil
+}
+
+// writeManifest writes the manifest of this run, sorted by path.
+func (g *generator) writeManifest() error {
+	var manifest Manifest
+	for _, entry := range g.manifestEntries {
+		manifest.Files = append(manifest.Files, entry)
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+
+	data, err := json.MarshalIndent(&manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+	return g.FS.WriteFile(filepath.Join(g.ProjectDir, manifestName), append(data, '\n'),
+		0644)
+}
+
+// removeOrphans deletes the files in the previous manifest that were not
+// produced by this run and adds them to the report.  A file that has been
+// changed since it was generated is left alone with a warning, and so is a
+// file that belongs to the user once it's created.  Directories emptied by
+// the removal are removed too.  In dry run mode, it only reports what it
+// would do.  The manifest could have been edited, so an entry outside the
+// project directory is ignored.
+func (g *generator) removeOrphans() {
+	for _, entry := range g.previousManifest.Files {
+		if _, ok := g.manifestEntries[entry.Path]; ok {
+			continue
+		}
+
+		if !isProjectPath(filepath.FromSlash(entry.Path)) {
+			g.warn("%s in %s is not inside the project directory, so it has been ignored",
+				entry.Path, manifestName)
+			continue
+		}
+
+		if entry.CreateOnce || g.createOnce[entry.Template] {
+			g.warn("%s is no longer generated but it belongs to you, so it has not been removed",
+				entry.Path)
+			continue
+		}
+
+		pathName := filepath.Join(g.ProjectDir, filepath.FromSlash(entry.Path))
+		content, err := g.FS.ReadFile(pathName)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				g.warn("cannot read orphaned file %s - %s", entry.Path, err.Error())
+			}
+			continue
+		}
+
+		if checksum(content) != entry.Checksum {
+			g.warn("%s is no longer generated but it has been changed by hand, so it has not been removed",
+				entry.Path)
+			// Keep it in the manifest so that the warning is repeated until
+			// the user deals with the file.
+			g.manifestEntries[entry.Path] = entry
+			continue
+		}
+
+		if !g.DryRun && !g.Diff {
+			g.logf("removing orphaned file %s", entry.Path)
+			err = g.FS.Remove(pathName)
+			if err != nil {
+				g.warn("cannot remove orphaned file %s - %s", entry.Path, err.Error())
+				continue
+			}
+			g.removeEmptyDirectories(filepath.Dir(pathName))
+		}
+		g.report.Files = append(g.report.Files,
+			FileReport{Path: filepath.Clean(pathName), Action: ActionRemove})
+	}
+}
+
+// removeEmptyDirectories removes the directory and then its parents for as
+// long as they are empty, stopping at the project directory.
+func (g *generator) removeEmptyDirectories(dir string) {
+	top := filepath.Clean(g.ProjectDir)
+	for dir = filepath.Clean(dir); dir != top && dir != "." && dir != "/"; dir = filepath.Dir(dir) {
+		// Remove fails if the directory is not empty.
+		if g.FS.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// manifestPath returns the path of the target file relative to the project
+// directory.
+func (g *generator) manifestPath(targetPathName string) string {
+	path, err := filepath.Rel(g.ProjectDir, targetPathName)
+	if err != nil {
+		return filepath.ToSlash(filepath.Clean(targetPathName))
+	}
+	return filepath.ToSlash(path)
+}
+
+// checksum returns the SHA-256 checksum of the content in hex.
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
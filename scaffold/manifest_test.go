@@ -0,0 +1,127 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+// twoResourceSpec is testSpec with a second resource, which the manifest
+// tests remove.
+var twoResourceSpec = strings.Replace(testSpec, `
+    ]
+}`, `,
+        {
+            "name": "mouse",
+            "fields": [
+                {"name": "name", "type": "string", "mandatory": true}
+            ]
+        }
+    ]
+}`, 1)
+
+// hasWarning returns true if one of the warnings contains the text.
+func hasWarning(report Report, text string) bool {
+	for _, warning := range report.Warnings {
+		if strings.Contains(warning, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRemoveOrphans(t *testing.T) {
+	const modelFile = "generated/crud/models/mouse/mouse.go"
+	const hooksFile = "hooks/mouse/hooks.go"
+	const controllerFile = "generated/crud/controllers/mouse/controller.go"
+
+	var testData = []struct {
+		description string
+		dryRun      bool
+		edit        string // a generated file to change by hand first
+		wantRemoved []string
+		wantKept    []string
+		wantWarning []string
+	}{
+		{"remove", false, "",
+			[]string{modelFile, controllerFile}, []string{hooksFile},
+			[]string{hooksFile + " is no longer generated but it belongs to you"}},
+		{"dry run", true, "",
+			nil, []string{modelFile, controllerFile, hooksFile},
+			[]string{hooksFile + " is no longer generated but it belongs to you"}},
+		{"changed by hand", false, controllerFile,
+			[]string{modelFile}, []string{controllerFile, hooksFile},
+			[]string{controllerFile + " is no longer generated but it has been changed by hand"}},
+	}
+
+	for _, td := range testData {
+		fsys, _ := generateTestProject(t, twoResourceSpec, Options{})
+		if td.edit != "" {
+			fsys.WriteFile(td.edit, []byte("// mine\n"), 0666)
+		}
+		spec := loadTestSpec(t, fsys, testSpec)
+		report, err := Generate(spec, Options{FS: fsys, DryRun: td.dryRun})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		removed := make(map[string]bool)
+		for _, file := range report.Files {
+			if file.Action == ActionRemove {
+				removed[file.Path] = true
+			}
+		}
+		for _, path := range td.wantRemoved {
+			if fileExists(fsys, path) || !removed[path] {
+				t.Errorf("%s: %s was not removed", td.description, path)
+			}
+		}
+		for _, path := range td.wantKept {
+			if !fileExists(fsys, path) {
+				t.Errorf("%s: %s was removed", td.description, path)
+			}
+			if !td.dryRun && removed[path] {
+				t.Errorf("%s: %s is reported as removed", td.description, path)
+			}
+		}
+		for _, warning := range td.wantWarning {
+			if !hasWarning(report, warning) {
+				t.Errorf("%s: want warning %q, got %v", td.description, warning, report.Warnings)
+			}
+		}
+	}
+}
+
+func TestRemoveOrphansWarnsOnceForCreateOnceFile(t *testing.T) {
+	fsys, _ := generateTestProject(t, twoResourceSpec, Options{})
+	spec := loadTestSpec(t, fsys, testSpec)
+	for run := 1; run <= 2; run++ {
+		report, err := Generate(spec, Options{FS: fsys})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := run == 1
+		if got := hasWarning(report, "hooks/mouse/hooks.go"); got != want {
+			t.Errorf("run %d: want warning %v, got %v", run, want, report.Warnings)
+		}
+	}
+}
+
+func TestRemoveOrphansStaysInProject(t *testing.T) {
+	fsys := &MemFS{}
+	spec := loadTestSpec(t, fsys, testSpec)
+	fsys.WriteFile("outside.go", []byte("package outside\n"), 0666)
+	manifest := `{"files": [{"path": "../outside.go", "template": "model.go.template",
+	    "checksum": "` + checksum([]byte("package outside\n")) + `"}]}`
+	fsys.WriteFile("proj/"+manifestName, []byte(manifest), 0644)
+
+	report, err := Generate(spec, Options{FS: fsys, ProjectDir: "proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fileExists(fsys, "outside.go") {
+		t.Error("a file outside the project was removed")
+	}
+	if !hasWarning(report, "not inside the project directory") {
+		t.Errorf("want a warning about the manifest entry, got %v", report.Warnings)
+	}
+}
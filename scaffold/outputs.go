@@ -0,0 +1,311 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// The output list says which files the scaffolder produces.  Each entry names
+// a template, says whether it's run once for the whole spec or once for each
+// resource, gives the path of the target file relative to the project
+// directory and says whether an existing file is overwritten.  It can also
+// give the file's permissions and the imports for a Go source file.  The
+// path and the imports are themselves templates, executed with the same data
+// as the main template, so for example:
+//
+//	{
+//	    "template": "controller.go.template",
+//	    "scope": "resource",
+//	    "path": "generated/crud/controllers/{{.NameAllLower}}/controller.go",
+//	    "overwrite": "always",
+//	    "imports": ["fmt", "restful github.com/emicklei/go-restful"]
+//	}
+//
+// The built-in list is templates/outputs.json.  A template pack (a
+// TemplateDir directory or the project's scaffold-templates directory) can
+// contain its own outputs.json, which replaces the built-in list, and any
+// extra templates that it needs.
+
+// OutputListName is the name of the file containing the output list.
+const OutputListName = "outputs.json"
+
+// The scopes of an output.
+const scopeSpec = "spec"
+const scopeResource = "resource"
+
+// The overwrite policies of an output.  An "always" file is regenerated every
+// time.  An "ifmissing" file belongs to the user once it's created, so it's
+// only replaced in overwrite mode.
+const overwriteAlways = "always"
+const overwriteIfMissing = "ifmissing"
+
+// Output describes one file (or one file per resource) to be generated.
+// Imports is a list of import specs, each a path optionally preceded by a
+// name, for example "restful github.com/emicklei/go-restful".  In an output
+// with spec scope, ResourceImports are added once for each resource.  A line
+// starting with "//" is copied into the import block as a comment.
+type Output struct {
+	Template        string   `json:"template"`
+	Scope           string   `json:"scope"`
+	Path            string   `json:"path"`
+	Overwrite       string   `json:"overwrite"`
+	Mode            string   `json:"mode,omitempty"`
+	Imports         []string `json:"imports,omitempty"`
+	ResourceImports []string `json:"resourceImports,omitempty"`
+}
+
+// OutputList is the list of outputs, in the order that they are generated.
+type OutputList struct {
+	Outputs []Output `json:"outputs"`
+}
+
+// loadOutputList reads the output list from the first of these that exists:
+//
+//	outputs.json in the project's scaffold-templates directory
+//	outputs.json in the TemplateDir directory, if specified
+//	the built-in outputs.json
+//
+// and checks it.  The templates must already have been created.
+func (g *generator) loadOutputList() (OutputList, error) {
+	var outputList OutputList
+	source := "built-in " + OutputListName
+	var data []byte
+	var err error
+	listFile := filepath.Join(g.ProjectDir, ProjectTemplateDir, OutputListName)
+	if !fileExists(g.FS, listFile) && g.TemplateDir != "" {
+		listFile = filepath.Join(g.TemplateDir, OutputListName)
+	}
+	if fileExists(g.FS, listFile) {
+		source = listFile
+		data, err = g.FS.ReadFile(listFile)
+	} else {
+		data, err = builtInTemplates.ReadFile("templates/" + OutputListName)
+	}
+	if err != nil {
+		return outputList, fmt.Errorf("cannot read output list %s - %s", source,
+			err.Error())
+	}
+	g.logf("using output list %s", source)
+
+	err = json.Unmarshal(data, &outputList)
+	if err != nil {
+		return outputList, fmt.Errorf("cannot parse output list %s - %s", source,
+			err.Error())
+	}
+
+	err = g.checkOutputList(outputList)
+	if err != nil {
+		return outputList, fmt.Errorf("output list %s - %s", source, err.Error())
+	}
+
+	// Note the templates of the files that belong to the user once they are
+	// created, and warn about any of the project's own templates that are
+	// not used - most likely a typing error.
+	used := make(map[string]bool)
+	for _, output := range outputList.Outputs {
+		used[output.Template] = true
+		if output.Overwrite == overwriteIfMissing {
+			g.createOnce[output.Template] = true
+		}
+	}
+	templateNames := make([]string, 0, len(g.packTemplates))
+	for templateName := range g.packTemplates {
+		templateNames = append(templateNames, templateName)
+	}
+	sort.Strings(templateNames)
+	for _, templateName := range templateNames {
+		if !used[templateName] {
+			g.warn("%s is not used by the output list and is ignored",
+				g.packTemplates[templateName])
+		}
+	}
+
+	return outputList, nil
+}
+
+// checkOutputList checks that each output in the list is valid.
+func (g *generator) checkOutputList(outputList OutputList) error {
+	if len(outputList.Outputs) == 0 {
+		return fmt.Errorf("no outputs")
+	}
+	for i, output := range outputList.Outputs {
+		where := fmt.Sprintf("output %d (%s)", i+1, output.Template)
+		if output.Template == "" {
+			return fmt.Errorf("output %d has no template", i+1)
+		}
+		if g.templates[output.Template] == nil {
+			return fmt.Errorf("%s - there is no template called %s", where,
+				output.Template)
+		}
+		if output.Scope != scopeSpec && output.Scope != scopeResource {
+			return fmt.Errorf("%s - scope must be \"%s\" or \"%s\", not \"%s\"",
+				where, scopeSpec, scopeResource, output.Scope)
+		}
+		if output.Path == "" {
+			return fmt.Errorf("%s has no path", where)
+		}
+		if !isProjectPath(output.Path) {
+			return fmt.Errorf("%s - path %s must be inside the project directory",
+				where, output.Path)
+		}
+		if output.Overwrite != overwriteAlways && output.Overwrite != overwriteIfMissing {
+			return fmt.Errorf("%s - overwrite must be \"%s\" or \"%s\", not \"%s\"",
+				where, overwriteAlways, overwriteIfMissing, output.Overwrite)
+		}
+		if output.Mode != "" {
+			_, err := strconv.ParseUint(output.Mode, 8, 32)
+			if err != nil {
+				return fmt.Errorf("%s - mode %s is not an octal number", where,
+					output.Mode)
+			}
+		}
+		if output.Scope == scopeResource && len(output.ResourceImports) > 0 {
+			return fmt.Errorf("%s - resourceImports can only be used with scope \"%s\"",
+				where, scopeSpec)
+		}
+	}
+	return nil
+}
+
+// generate produces the files in the output list from the templates and the
+// enhanced spec.
+func (g *generator) generate(spec Spec, outputList OutputList) error {
+	for _, output := range outputList.Outputs {
+		if output.Scope == scopeSpec {
+			imports, err := g.expandImports(output, output.Imports, spec)
+			if err != nil {
+				return err
+			}
+			for _, resource := range spec.Resources {
+				resourceImports, err := g.expandImports(output, output.ResourceImports,
+					resource)
+				if err != nil {
+					return err
+				}
+				imports = append(imports, resourceImports...)
+			}
+			spec.Imports = formatImports(imports)
+			err = g.generateOutput(output, spec)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, resource := range spec.Resources {
+			imports, err := g.expandImports(output, output.Imports, resource)
+			if err != nil {
+				return err
+			}
+			resource.Imports = formatImports(imports)
+			err = g.generateOutput(output, resource)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// generateOutput produces one file from the output and the data, which is a
+// Spec or a Resource.
+func (g *generator) generateOutput(output Output, data interface{}) error {
+	path, err := g.expandPattern(output.Path, data)
+	if err != nil {
+		return fmt.Errorf("output %s - cannot work out the path - %s",
+			output.Template, err.Error())
+	}
+	if !isProjectPath(path) {
+		// The pattern was checked, but the data could still take it outside.
+		return fmt.Errorf("output %s - path %s is not inside the project directory",
+			output.Template, path)
+	}
+	targetPathName := filepath.Join(g.ProjectDir, path)
+
+	overwrite := output.Overwrite == overwriteAlways || g.Overwrite
+	err = g.createFileFromTemplate(targetPathName, output.Template, data, overwrite)
+	if err != nil {
+		return err
+	}
+
+	if output.Mode != "" && !g.DryRun && !g.Diff {
+		// The mode has already been checked.
+		mode, _ := strconv.ParseUint(output.Mode, 8, 32)
+		g.FS.Chmod(targetPathName, os.FileMode(mode))
+	}
+	return nil
+}
+
+// isProjectPath returns true if the path, which is relative to the project
+// directory, names a file inside it.  Once it's cleaned, it must not be
+// absolute, the project directory itself or start with "..".
+func isProjectPath(path string) bool {
+	clean := filepath.Clean(path)
+	return !filepath.IsAbs(clean) && clean != "." && clean != ".." &&
+		!strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
+
+// expandImports executes each of the import patterns using the data.
+func (g *generator) expandImports(output Output, patterns []string, data interface{}) ([]string, error) {
+	imports := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		spec, err := g.expandPattern(pattern, data)
+		if err != nil {
+			return nil, fmt.Errorf("output %s - cannot work out import %s - %s",
+				output.Template, pattern, err.Error())
+		}
+		// An import that expands to nothing is left out, so an import can
+		// depend on the spec: "{{if .HasPatterns}}regexp{{end}}".
+		if strings.TrimSpace(spec) != "" {
+			imports = append(imports, spec)
+		}
+	}
+	return imports, nil
+}
+
+// formatImports turns a list of import specs into a Go import declaration,
+// or "" if the list is empty.
+func formatImports(imports []string) string {
+	if len(imports) == 0 {
+		return ""
+	}
+	var result strings.Builder
+	result.WriteString("import (\n")
+	for _, spec := range imports {
+		spec = strings.TrimSpace(spec)
+		if strings.HasPrefix(spec, "//") {
+			result.WriteString("\t" + spec + "\n")
+			continue
+		}
+		fields := strings.Fields(spec)
+		if len(fields) == 2 {
+			result.WriteString("\t" + fields[0] + " \"" + fields[1] + "\"\n")
+		} else {
+			result.WriteString("\t\"" + spec + "\"\n")
+		}
+	}
+	result.WriteString(")")
+	return result.String()
+}
+
+// expandPattern executes a small template such as a path or an import spec
+// using the data.
+func (g *generator) expandPattern(pattern string, data interface{}) (string, error) {
+	t, err := template.New("pattern").Funcs(g.funcs).Parse(pattern)
+	if err != nil {
+		return "", err
+	}
+	var result bytes.Buffer
+	err = t.Execute(&result, data)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
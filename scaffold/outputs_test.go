@@ -0,0 +1,55 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsProjectPath(t *testing.T) {
+	var testData = []struct {
+		path string
+		want bool
+	}{
+		{"install.sh", true},
+		{"generated/crud/models/cat/cat.go", true},
+		{"./views/../go.mod", true},
+		{"a/..b/c", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../x", false},
+		{"../../etc/x", false},
+		{"generated/../../x", false},
+		{"/etc/passwd", false},
+	}
+
+	for _, td := range testData {
+		if got := isProjectPath(td.path); got != td.want {
+			t.Errorf("isProjectPath(%q) - want %v, got %v", td.path, td.want, got)
+		}
+	}
+}
+
+func TestOutputListRejectsPathOutsideProject(t *testing.T) {
+	var testData = []string{
+		"../../etc/x",
+		"/tmp/x",
+		"generated/../../x",
+	}
+
+	for _, path := range testData {
+		fsys := &MemFS{}
+		spec := loadTestSpec(t, fsys, testSpec)
+		list := `{"outputs": [{"template": "go.mod.template", "scope": "spec", "path": "` +
+			path + `", "overwrite": "always"}]}`
+		fsys.WriteFile(ProjectTemplateDir+"/"+OutputListName, []byte(list), 0644)
+
+		_, err := Generate(spec, Options{FS: fsys})
+		if err == nil || !strings.Contains(err.Error(), "inside the project directory") {
+			t.Errorf("%s: want an error about the path, got %v", path, err)
+		}
+		if len(fsys.Files()) != 2 {
+			t.Errorf("%s: want no files written, got %v", path, fsys.Files())
+		}
+	}
+}
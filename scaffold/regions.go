@@ -0,0 +1,163 @@
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Protected regions allow the user to edit a generated file and keep the
+// changes when the scaffolder is run again.  A region starts with a line
+// containing "scaffolder:begin" followed by the name of the region and ends
+// with a line containing "scaffolder:end".  The markers are normally in
+// comments, for example in a Go file:
+//
+//    // scaffolder:begin custom
+//    ... user's code ...
+//    // scaffolder:end
+//
+// and in a .ghtml file:
+//
+//    <!-- scaffolder:begin custom -->
+//    ... user's HTML ...
+//    <!-- scaffolder:end -->
+//
+// When a file is regenerated, the contents of each region in the existing
+// file replace the contents of the region with the same name in the new
+// output.  The marker lines themselves always come from the new output.
+
+const regionBeginMarker = "scaffolder:begin"
+const regionEndMarker = "scaffolder:end"
+
+// spliceProtectedRegions takes the newly generated content of a file and
+// replaces the contents of its protected regions with the saved ones.  A
+// region in the new content with no saved version keeps its generated
+// contents.  A saved region that is not in the new content is lost, so a
+// warning is added to the report.
+func (g *generator) spliceProtectedRegions(generated []byte, saved map[string]string,
+	pathName string) ([]byte, error) {
+
+	if len(saved) == 0 {
+		return generated, nil
+	}
+
+	_, names, err := parseProtectedRegions(generated, pathName+" (generated)")
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	for _, name := range names {
+		used[name] = true
+	}
+	for name := range saved {
+		if !used[name] {
+			g.warn("%s - protected region %s is no longer generated, its contents are lost",
+				pathName, name)
+		}
+	}
+
+	var result bytes.Buffer
+	region := "" // the name of the region being copied, "" if none
+	for _, line := range splitLines(generated) {
+		switch {
+		case region == "" && strings.Contains(line, regionBeginMarker):
+			result.WriteString(line)
+			name := regionName(line)
+			if contents, ok := saved[name]; ok {
+				result.WriteString(contents)
+				region = name
+			}
+		case region != "" && strings.Contains(line, regionEndMarker):
+			result.WriteString(line)
+			region = ""
+		case region != "":
+			// Skip the generated contents of a saved region.
+		default:
+			result.WriteString(line)
+		}
+	}
+
+	return result.Bytes(), nil
+}
+
+// parseProtectedRegions finds the protected regions in the content and
+// returns their contents keyed by name, plus the names in order.  The file
+// name is only used in error messages.
+func parseProtectedRegions(content []byte, pathName string) (map[string]string, []string, error) {
+	regions := make(map[string]string)
+	names := make([]string, 0)
+
+	region := "" // the name of the current region, "" if none
+	start := 0   // the line number of the start of the current region
+	var contents strings.Builder
+	for i, line := range splitLines(content) {
+		lineNumber := i + 1
+		if strings.Contains(line, regionBeginMarker) {
+			if region != "" {
+				return nil, nil, fmt.Errorf("%s:%d: protected region %s started inside region %s (line %d)",
+					pathName, lineNumber, regionName(line), region, start)
+			}
+			region = regionName(line)
+			if region == "" {
+				return nil, nil, fmt.Errorf("%s:%d: protected region has no name",
+					pathName, lineNumber)
+			}
+			if _, ok := regions[region]; ok {
+				return nil, nil, fmt.Errorf("%s:%d: protected region %s appears twice",
+					pathName, lineNumber, region)
+			}
+			start = lineNumber
+			contents.Reset()
+			continue
+		}
+		if strings.Contains(line, regionEndMarker) {
+			if region == "" {
+				return nil, nil, fmt.Errorf("%s:%d: end of protected region without a start",
+					pathName, lineNumber)
+			}
+			regions[region] = contents.String()
+			names = append(names, region)
+			region = ""
+			continue
+		}
+		if region != "" {
+			contents.WriteString(line)
+		}
+	}
+
+	if region != "" {
+		return nil, nil, fmt.Errorf("%s:%d: protected region %s is not ended",
+			pathName, start, region)
+	}
+
+	return regions, names, nil
+}
+
+// regionName returns the name of the region from a line containing the
+// begin marker, which is the first word after the marker.
+func regionName(line string) string {
+	i := strings.Index(line, regionBeginMarker)
+	words := strings.Fields(line[i+len(regionBeginMarker):])
+	if len(words) == 0 || strings.HasPrefix(words[0], "-->") || strings.HasPrefix(words[0], "*/") {
+		return ""
+	}
+	return words[0]
+}
+
+// splitLines splits the content into lines, keeping the line endings.
+func splitLines(content []byte) []string {
+	lines := make([]string, 0)
+	reader := bufio.NewReader(bytes.NewReader(content))
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return lines
+}
@@ -0,0 +1,100 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProtectedRegions(t *testing.T) {
+	var testData = []struct {
+		description string
+		content     string
+		want        map[string]string
+		wantNames   []string
+		wantError   string
+	}{
+		{"none", "a\nb\n", map[string]string{}, []string{}, ""},
+		{"go",
+			"a\n// scaffolder:begin custom\nmine\n// scaffolder:end\nb\n",
+			map[string]string{"custom": "mine\n"}, []string{"custom"}, ""},
+		{"html",
+			"<!-- scaffolder:begin top -->\n<p>x</p>\n<!-- scaffolder:end -->\n" +
+				"<!-- scaffolder:begin bottom -->\n<!-- scaffolder:end -->\n",
+			map[string]string{"top": "<p>x</p>\n", "bottom": ""}, []string{"top", "bottom"}, ""},
+		{"no name", "// scaffolder:begin\n// scaffolder:end\n", nil, nil,
+			"f.go:1: protected region has no name"},
+		{"html no name", "<!-- scaffolder:begin -->\n<!-- scaffolder:end -->\n", nil, nil,
+			"f.go:1: protected region has no name"},
+		{"nested", "// scaffolder:begin a\n// scaffolder:begin b\n", nil, nil,
+			"f.go:2: protected region b started inside region a (line 1)"},
+		{"twice", "// scaffolder:begin a\n// scaffolder:end\n// scaffolder:begin a\n// scaffolder:end\n",
+			nil, nil, "f.go:3: protected region a appears twice"},
+		{"no start", "x\n// scaffolder:end\n", nil, nil,
+			"f.go:2: end of protected region without a start"},
+		{"not ended", "// scaffolder:begin a\nx\n", nil, nil,
+			"f.go:1: protected region a is not ended"},
+	}
+
+	for _, td := range testData {
+		regions, names, err := parseProtectedRegions([]byte(td.content), "f.go")
+		if td.wantError != "" {
+			if err == nil || err.Error() != td.wantError {
+				t.Errorf("%s: want error %q, got %v", td.description, td.wantError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", td.description, err)
+			continue
+		}
+		if strings.Join(names, ",") != strings.Join(td.wantNames, ",") {
+			t.Errorf("%s: want names %v, got %v", td.description, td.wantNames, names)
+		}
+		if len(regions) != len(td.want) {
+			t.Errorf("%s: want %d regions, got %d", td.description, len(td.want), len(regions))
+		}
+		for name, contents := range td.want {
+			if regions[name] != contents {
+				t.Errorf("%s: region %s - want %q, got %q", td.description, name, contents,
+					regions[name])
+			}
+		}
+	}
+}
+
+func TestSpliceProtectedRegions(t *testing.T) {
+	const generated = "top\n// scaffolder:begin a\ndefault a\n// scaffolder:end\n" +
+		"middle\n// scaffolder:begin b\ndefault b\n// scaffolder:end\nbottom\n"
+
+	var testData = []struct {
+		description string
+		saved       map[string]string
+		want        string
+		wantWarning bool
+	}{
+		{"nothing saved", nil, generated, false},
+		{"one saved", map[string]string{"b": "mine\nmore\n"},
+			"top\n// scaffolder:begin a\ndefault a\n// scaffolder:end\n" +
+				"middle\n// scaffolder:begin b\nmine\nmore\n// scaffolder:end\nbottom\n", false},
+		{"emptied", map[string]string{"a": ""},
+			"top\n// scaffolder:begin a\n// scaffolder:end\n" +
+				"middle\n// scaffolder:begin b\ndefault b\n// scaffolder:end\nbottom\n", false},
+		{"lost", map[string]string{"gone": "mine\n"}, generated, true},
+	}
+
+	for _, td := range testData {
+		g := generator{}
+		got, err := g.spliceProtectedRegions([]byte(generated), td.saved, "f.go")
+		if err != nil {
+			t.Errorf("%s: %v", td.description, err)
+			continue
+		}
+		if string(got) != td.want {
+			t.Errorf("%s: want\n%s\ngot\n%s", td.description, td.want, got)
+		}
+		if (len(g.report.Warnings) > 0) != td.wantWarning {
+			t.Errorf("%s: want warning %v, got %v", td.description, td.wantWarning,
+				g.report.Warnings)
+		}
+	}
+}
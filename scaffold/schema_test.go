@@ -0,0 +1,333 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// The JSON Schema for the spec file lives at the top of the repository (see
+// schema.go).  These tests check that it agrees with the Go types that the spec
+// is decoded into, and that the specs used elsewhere satisfy it.
+
+// readSchema reads and decodes the schema.
+func readSchema(t *testing.T) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile("../scaffold.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	err = json.Unmarshal(data, &schema)
+	if err != nil {
+		t.Fatalf("cannot decode the schema - %v", err)
+	}
+	return schema
+}
+
+func TestSchemaMatchesTypes(t *testing.T) {
+	schema := readSchema(t)
+	checked := make(map[reflect.Type]bool)
+	compareWithSchema(t, schema, reflect.TypeOf(Spec{}), schema, "spec", checked)
+
+	// Every struct that the spec is decoded into was reached.
+	for _, value := range []interface{}{Spec{}, Resource{}, Field{}, FieldType{}, Inflections{}} {
+		if !checked[reflect.TypeOf(value)] {
+			t.Errorf("%T is not described by the schema", value)
+		}
+	}
+}
+
+// compareWithSchema checks that the JSON names of the struct's fields are the
+// same as the properties of the schema node, then does the same for each of
+// the fields that holds structs.
+func compareWithSchema(t *testing.T, schema map[string]interface{}, structType reflect.Type,
+	node map[string]interface{}, path string, checked map[reflect.Type]bool) {
+
+	t.Helper()
+	checked[structType] = true
+
+	if node["additionalProperties"] != false {
+		t.Errorf("%s: the schema allows properties that the spec doesn't have", path)
+	}
+	properties, _ := node["properties"].(map[string]interface{})
+	if properties == nil {
+		t.Errorf("%s: the schema has no properties", path)
+		return
+	}
+
+	names := make([]string, 0)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+
+		property, _ := properties[name].(map[string]interface{})
+		if property == nil {
+			continue
+		}
+
+		// Find the schema for the structs, if any, that the field holds.
+		property = resolveRef(t, schema, property)
+		fieldType := field.Type
+		switch fieldType.Kind() {
+		case reflect.Slice, reflect.Array:
+			fieldType = fieldType.Elem()
+			property, _ = property["items"].(map[string]interface{})
+		case reflect.Map:
+			fieldType = fieldType.Elem()
+			property, _ = property["additionalProperties"].(map[string]interface{})
+			if fieldType.Kind() == reflect.Slice {
+				fieldType = fieldType.Elem()
+				if property != nil {
+					property, _ = property["items"].(map[string]interface{})
+				}
+			}
+		}
+		if fieldType.Kind() != reflect.Struct {
+			continue
+		}
+		if property == nil {
+			t.Errorf("%s.%s: the schema doesn't describe the %s objects", path, name, fieldType.Name())
+			continue
+		}
+		compareWithSchema(t, schema, fieldType, resolveRef(t, schema, property),
+			path+"."+name, checked)
+	}
+
+	schemaNames := make([]string, 0, len(properties))
+	for name := range properties {
+		schemaNames = append(schemaNames, name)
+	}
+	sort.Strings(names)
+	sort.Strings(schemaNames)
+	if !reflect.DeepEqual(names, schemaNames) {
+		t.Errorf("%s: %s has the JSON names\n%v\nbut the schema has the properties\n%v",
+			path, structType.Name(), names, schemaNames)
+	}
+}
+
+// resolveRef returns the definition that the node refers to, if it's a
+// reference, otherwise the node itself.
+func resolveRef(t *testing.T, schema map[string]interface{}, node map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node
+	}
+	const prefix = "#/definitions/"
+	if !strings.HasPrefix(ref, prefix) {
+		t.Fatalf("cannot follow the reference %q", ref)
+	}
+	definitions, _ := schema["definitions"].(map[string]interface{})
+	definition, _ := definitions[strings.TrimPrefix(ref, prefix)].(map[string]interface{})
+	if definition == nil {
+		t.Fatalf("there is no definition for the reference %q", ref)
+	}
+	return definition
+}
+
+func TestSpecsSatisfySchema(t *testing.T) {
+	schema := readSchema(t)
+
+	example, err := os.ReadFile("../examples/animals.scaffold.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var testData = []struct {
+		description string
+		file        string
+		text        string
+	}{
+		{"example", "animals.scaffold.json", string(example)},
+		{"testSpec", "scaffold.json", testSpec},
+		{"twoResourceSpec", "scaffold.json", twoResourceSpec},
+		{"checkSpecText", "scaffold.json", checkSpecWith(goodField)},
+		{"fieldSetSpec", "scaffold.json", strings.Replace(fieldSetSpec, "%s",
+			`{"name": "customer", "fieldSets": ["audit", "address"],
+                "fields": [{"name": "name", "type": "string", "mandatory": true}]}`, 1)},
+		{"includingSpec", "scaffold.json", strings.Replace(includingSpec, "%s", `"resources/*.json"`, 1)},
+		{"yamlSpec", "scaffold.yaml", yamlSpec},
+		{"tomlSpec", "scaffold.toml", tomlSpec},
+	}
+
+	for _, td := range testData {
+		data, _, problem := convertSpec(td.file, []byte(td.text))
+		if problem != nil {
+			t.Errorf("%s: %s", td.description, problem.String())
+			continue
+		}
+		var value interface{}
+		err := json.Unmarshal(data, &value)
+		if err != nil {
+			t.Errorf("%s: %v", td.description, err)
+			continue
+		}
+		for _, e := range validate(schema, schema, value, "") {
+			t.Errorf("%s: %s", td.description, e)
+		}
+	}
+
+	// The validator isn't just saying yes.
+	var bad interface{}
+	err = json.Unmarshal([]byte(`{"name": "animals", "sourcebase": "x", "db": "postgres",
+		"resources": [{"name": "cat", "fields": [{"name": "age", "type": "int", "testValues": ["x"], "size": 3}]}]}`), &bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`: "dbuser" is missing`,
+		`db: "postgres" is not one of [mysql]`,
+		`resources[0].fields[0].testValues[0]: "x" does not match ^[-+]?[0-9]+$`,
+		`resources[0].fields[0]: unknown property "size"`,
+	}
+	got := validate(schema, schema, bad, "")
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bad spec: want\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+// validate checks a decoded JSON value against a node of the schema and
+// returns a description of each way in which it fails.  It only understands
+// the parts of JSON Schema that scaffold.schema.json uses.
+func validate(schema map[string]interface{}, node map[string]interface{}, value interface{}, path string) []string {
+	errors := make([]string, 0)
+	fail := func(format string, args ...interface{}) {
+		errors = append(errors, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if ref, ok := node["$ref"].(string); ok {
+		definitions := schema["definitions"].(map[string]interface{})
+		definition := definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]interface{})
+		errors = append(errors, validate(schema, definition, value, path)...)
+	}
+
+	if want, ok := node["type"].(string); ok && !hasJSONType(value, want) {
+		fail("%v is not of type %s", value, want)
+		return errors
+	}
+	if enum, ok := node["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if reflect.DeepEqual(value, allowed) {
+				found = true
+			}
+		}
+		if !found {
+			fail("%q is not one of %v", value, enum)
+		}
+	}
+	if want, ok := node["const"]; ok && !reflect.DeepEqual(value, want) {
+		fail("%v is not %v", value, want)
+	}
+
+	switch v := value.(type) {
+	case string:
+		if pattern, ok := node["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(v) {
+			fail("%q does not match %s", v, pattern)
+		}
+		if min, ok := node["minLength"].(float64); ok && float64(len(v)) < min {
+			fail("%q is shorter than %v", v, min)
+		}
+	case float64:
+		if min, ok := node["minimum"].(float64); ok && v < min {
+			fail("%v is less than %v", v, min)
+		}
+	case []interface{}:
+		if min, ok := node["minItems"].(float64); ok && float64(len(v)) < min {
+			fail("there are fewer than %v items", min)
+		}
+		if items, ok := node["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				errors = append(errors, validate(schema, items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case map[string]interface{}:
+		required, _ := node["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				fail("%q is missing", name)
+			}
+		}
+		properties, _ := node["properties"].(map[string]interface{})
+		for name, item := range v {
+			itemPath := name
+			if path != "" {
+				itemPath = path + "." + name
+			}
+			if property, ok := properties[name].(map[string]interface{}); ok {
+				errors = append(errors, validate(schema, property, item, itemPath)...)
+				continue
+			}
+			switch additional := node["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					fail("unknown property %q", name)
+				}
+			case map[string]interface{}:
+				errors = append(errors, validate(schema, additional, item, itemPath)...)
+			}
+		}
+	}
+
+	if anyOf, ok := node["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, alternative := range anyOf {
+			if len(validate(schema, alternative.(map[string]interface{}), value, path)) == 0 {
+				matched = true
+			}
+		}
+		if !matched {
+			fail("%v matches none of the alternatives", value)
+		}
+	}
+	if allOf, ok := node["allOf"].([]interface{}); ok {
+		for _, part := range allOf {
+			errors = append(errors, validate(schema, part.(map[string]interface{}), value, path)...)
+		}
+	}
+	if condition, ok := node["if"].(map[string]interface{}); ok {
+		if len(validate(schema, condition, value, path)) == 0 {
+			if then, ok := node["then"].(map[string]interface{}); ok {
+				errors = append(errors, validate(schema, then, value, path)...)
+			}
+		}
+	}
+	return errors
+}
+
+// hasJSONType returns true if the decoded JSON value has the JSON Schema type.
+func hasJSONType(value interface{}, jsonType string) bool {
+	switch jsonType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	}
+	return false
+}
@@ -0,0 +1,356 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Spec describes a project: its name, where its source lives, its database
+// and its resources, each of which becomes a database table, a set of web
+// pages and the code to drive them.  It's normally read from a spec file by
+// LoadSpec.  The fields without a JSON name are derived from the others by
+// Enhance, and are what the templates use.
+
+type Field struct {
+	Name               string   `json:"name"`
+	Type               string   `json:"type"`
+	ExcludeFromDisplay bool     `json:"excludeFromDisplay"`
+	Mandatory          bool     `json:"mandatory"`
+	TestValues         []string `json:"testValues"`
+	Pattern            string   `json:"pattern"`
+	MaxLength          int      `json:"maxLength"`
+	Column             string   `json:"column"` // the database column, by default the name
+	TypeName           string   // the custom type, if any (see expandFields)
+	GoType             string
+	NameWithUpperFirst string
+	NameWithLowerFirst string
+	NameAllLower       string
+	LastItem           bool
+}
+
+func (f Field) String() string {
+	testValues := ""
+	for _, s := range f.TestValues {
+		if f.Type == "string" {
+			testValues += fmt.Sprintf("\"%s\",", s)
+		} else {
+			testValues += s
+		}
+	}
+
+	status := "optional"
+	if f.Mandatory {
+		status = "mandatory"
+	}
+	return fmt.Sprintf("{Name=%s,Type=%s,GoType=%s, ExcludeFromDisplay=%v,%s,TestValues=%s,NameWithLowerFirst=%s,NameWithUpperFirst=%s,NameAllLower=%s,LastItem=%v}",
+		f.Name, f.Type, f.GoType, f.ExcludeFromDisplay, status, testValues,
+		f.NameWithLowerFirst, f.NameWithUpperFirst, f.NameAllLower, f.LastItem)
+}
+
+type Resource struct {
+	Name                      string   `json:"name"`
+	PluralName                string   `json:"plural"`
+	TableName                 string   `json:"tableName"`
+	FieldSets                 []string `json:"fieldSets"`
+	NameWithUpperFirst        string
+	NameWithLowerFirst        string
+	NameAllLower              string
+	PluralNameWithUpperFirst  string
+	PluralNameWithLowerFirst  string
+	ProjectName               string // copied from the name field of the spec record
+	ProjectNameWithUpperFirst string
+	Imports                   string
+	SourceBase                string  // copied from the spec record
+	DB                        string  // copied from the spec record
+	DBURL                     string  // copied from the spec record
+	HasPatterns               bool    // true if any of the fields has a pattern
+	HasStrings                bool    // true if any of the fields is a string
+	HasMandatoryStrings       bool    // true if any of the mandatory fields is a string
+	Fields                    []Field `json:"fields"`
+}
+
+func (r Resource) String() string {
+	var fields string
+	for _, f := range r.Fields {
+		fields += f.String() + "\n"
+	}
+	return fmt.Sprintf("{Name=%s,PluralName=%s,TableName=%s,NameWithLowerFirst=%s,NameWithUpperFirst=%s,PluralNameWithLowerFirst=%s,PluralNameWithUpperFirst=%s,NameAllLower=%s,ProjectName=%s,imports=%s,DB=%s,DBURL=%s,fields=%s}",
+		r.Name, r.PluralName, r.TableName,
+		r.NameWithLowerFirst, r.NameWithUpperFirst,
+		r.PluralNameWithLowerFirst, r.PluralNameWithUpperFirst, r.NameAllLower,
+		r.ProjectName, r.Imports, r.DB, r.DBURL, fields)
+}
+
+type Spec struct {
+	Schema             string               `json:"$schema"` // for editors, see scaffold.schema.json
+	Name               string               `json:"name"`
+	SourceBase         string               `json:"sourcebase"`
+	DB                 string               `json:"db"`
+	DBUser             string               `json:"dbuser"`
+	DBPassword         string               `json:"dbpassword"`
+	DBServer           string               `json:"dbserver"`
+	DBPort             string               `json:"dbport"`
+	ORM                string               `json:"orm"`
+	Inflections        Inflections          `json:"inflections"`
+	Include            []string             `json:"include"` // see includeFragments
+	FieldSets          map[string][]Field   `json:"fieldSets"`
+	Types              map[string]FieldType `json:"types"`
+	DBURL              string
+	NameWithUpperFirst string
+	NameWithLowerFirst string
+	NameAllUpper       string
+	Imports            string
+	HasNonStrings      bool       // true if any resource has a field that is not a string
+	Resources          []Resource `json:"resources"`
+	enhanced           bool       // set by Enhance
+}
+
+func (s Spec) String() string {
+	var resources string
+	for _, r := range s.Resources {
+		resources += r.String() + "\n"
+	}
+	return fmt.Sprintf("{name=%s sourceBase=%s db=%s dbserver=%s dbport=%s dbuser=%s dbpassword=%s dburl=%s %d resources={%s}}",
+		s.Name, s.SourceBase, s.DB, s.DBServer, s.DBPort, s.DBUser, s.DBPassword,
+		s.DBURL, len(s.Resources), resources)
+}
+
+// enhanceSpec sets the derived fields of the spec, its resources and their
+// fields, such as the various forms of each name, and creates any test values
+// that the spec doesn't supply.  It returns any problems that it finds.
+func enhanceSpec(spec *Spec) []Problem {
+	problems := make([]Problem, 0)
+
+	// Use any words that the spec says are not pluralised by the usual rules.
+	inflections := newInflector(spec.Inflections)
+
+	if spec.DBPort == "" {
+		if spec.DB == "mysql" {
+			spec.DBPort = "3306"
+		}
+	}
+
+	// "webuser:secret@tcp(localhost:3306)/animals"
+	spec.DBURL = spec.DBUser + ":" + spec.DBPassword + "@tcp(" +
+		spec.DBServer + ":" + spec.DBPort + ")/" + spec.Name
+
+	// "animals" => "Animals"
+	spec.NameWithUpperFirst = upperFirstRune(spec.Name)
+	// Animals" => animals"
+	spec.NameWithLowerFirst = lowerFirstRune(spec.Name)
+	//"animals" => "ANIMALS"
+	spec.NameAllUpper = strings.ToUpper(spec.Name)
+
+	for i, _ := range spec.Resources {
+		// Set the last item flag in each field list.  For all but the last
+		// field in a resource, the LastItem flag is false.  For the last field
+		// it's true.  This helps the templates to construct things like lists
+		// where the fields are separated by commas but the last field is not
+		// followed by a comma, for example:
+		// return MakeInitialisedPerson(source.ID(), source.Forename(), source.Surname())
+		for j, _ := range spec.Resources[i].Fields {
+			// Set LastItem true, then set it false on the next iteration.
+			if j > 0 {
+				spec.Resources[i].Fields[j].LastItem = true
+				spec.Resources[i].Fields[j-1].LastItem = false
+			}
+		}
+
+		spec.NameWithUpperFirst = upperFirstRune(spec.Name)
+		spec.NameWithLowerFirst = lowerFirstRune(spec.Name)
+
+		// These are supplied once in the spec, but each resource needs them,
+		// so copy them into each resource record.
+		spec.Resources[i].ProjectName = spec.Name
+		spec.Resources[i].SourceBase = spec.SourceBase
+		// "animals" => "Animals"
+		spec.Resources[i].ProjectNameWithUpperFirst =
+			upperFirstRune(spec.Name)
+		spec.Resources[i].DB = spec.DB
+		spec.Resources[i].DBURL = spec.DBURL
+
+		// "CatAndDog" => "catAndDog"
+		spec.Resources[i].NameWithLowerFirst = lowerFirstRune(spec.Resources[i].Name)
+		// "cat" => "Cat"
+		spec.Resources[i].NameWithUpperFirst = upperFirstRune(spec.Resources[i].Name)
+
+		// "CatAndDog" => "catanddog"
+		spec.Resources[i].NameAllLower =
+			strings.ToLower(spec.Resources[i].Name)
+
+		if spec.Resources[i].PluralName == "" {
+			// "cat" => "cats", "mouse" => "mice"
+			spec.Resources[i].PluralName = inflections.plural(spec.Resources[i].NameWithLowerFirst)
+			if spec.Resources[i].PluralName == spec.Resources[i].NameWithLowerFirst {
+				problems = append(problems, Problem{
+					path:    fmt.Sprintf("resources[%d].name", i),
+					Message: "the plural of " + spec.Resources[i].Name + " is the same as the singular, which can produce clashing names - consider specifying \"plural\"",
+					Warning: true})
+			}
+		}
+
+		// "CatAndDogs" => "catAndDogs"
+		spec.Resources[i].PluralNameWithLowerFirst =
+			lowerFirstRune(spec.Resources[i].PluralName)
+
+		// "catAndDogs" => "CatAndDogs"
+		spec.Resources[i].PluralNameWithUpperFirst =
+			upperFirstRune(spec.Resources[i].PluralName)
+
+		// The table name is the plural of the lowered resource name (eg "cats")
+		// but the JSON can specify it (eg resource name is "mouse" and table
+		// name is "mice".
+		if spec.Resources[i].TableName == "" {
+			spec.Resources[i].TableName = spec.Resources[i].PluralName
+		}
+
+		// Set the fields that are set from other fields.
+		nextTestValue := 1
+
+		for j, _ := range spec.Resources[i].Fields {
+
+			// "int" => "int64" and so on.
+			spec.Resources[i].Fields[j].GoType =
+				goType(spec.Resources[i].Fields[j].Type)
+
+			spec.Resources[i].Fields[j].NameWithUpperFirst =
+				upperFirstRune(spec.Resources[i].Fields[j].Name)
+			spec.Resources[i].Fields[j].NameWithLowerFirst =
+				lowerFirstRune(spec.Resources[i].Fields[j].Name)
+			spec.Resources[i].Fields[j].NameAllLower =
+				strings.ToLower(spec.Resources[i].Fields[j].Name)
+
+			// The column is named after the field unless the spec says
+			// otherwise, for example to match an existing table.
+			if spec.Resources[i].Fields[j].Column == "" {
+				spec.Resources[i].Fields[j].Column =
+					spec.Resources[i].Fields[j].NameWithLowerFirst
+			}
+
+			if spec.Resources[i].Fields[j].Pattern != "" {
+				spec.Resources[i].HasPatterns = true
+			}
+
+			// Some packages are only imported if they are needed, for
+			// example strings for trimming the string fields.
+			if spec.Resources[i].Fields[j].Type == "string" {
+				spec.Resources[i].HasStrings = true
+				if spec.Resources[i].Fields[j].Mandatory {
+					spec.Resources[i].HasMandatoryStrings = true
+				}
+			} else {
+				spec.HasNonStrings = true
+			}
+
+			// The test values are optional.  We need two values for each
+			// field, because some tests create two objects.  If only one
+			// value is supplied, then use that and create the second. If
+			// none are supplied, then create both.  To create all values,
+			// use a sequence such as:
+			// {"s1", "s2}, {"s3", "s4"}, {"s5", "s6"} for three string types,
+			// or {"1.1", "2.1"}, {"s3", "s4"} for a float type followed by a
+			// string type.
+			//
+			// For booleans, generate {true, false}, {true, false} ....
+
+			CreateFirstTestValue := false
+			CreateSecondTestValue := false
+			switch len(spec.Resources[i].Fields[j].TestValues) {
+			case 0:
+				CreateFirstTestValue = true
+				CreateSecondTestValue = true
+			case 1:
+				// Got the first value, need the second.
+				CreateSecondTestValue = true
+			}
+			for len(spec.Resources[i].Fields[j].TestValues) < 2 {
+				spec.Resources[i].Fields[j].TestValues =
+					append(spec.Resources[i].Fields[j].TestValues, "")
+			}
+
+			switch spec.Resources[i].Fields[j].Type {
+			case "string":
+				// If the field has a maximum length, keep the end of the
+				// value, which is different for each value.
+				maxLength := spec.Resources[i].Fields[j].MaxLength
+				if CreateFirstTestValue {
+					spec.Resources[i].Fields[j].TestValues[0] =
+						lastRunes(fmt.Sprintf("s%d", nextTestValue), maxLength)
+				}
+				if CreateSecondTestValue {
+					spec.Resources[i].Fields[j].TestValues[1] =
+						lastRunes(fmt.Sprintf("s%d", nextTestValue+1), maxLength)
+				}
+			case "int":
+				if CreateFirstTestValue {
+					spec.Resources[i].Fields[j].TestValues[0] =
+						fmt.Sprintf("%d", nextTestValue)
+				}
+				if CreateSecondTestValue {
+					spec.Resources[i].Fields[j].TestValues[1] =
+						fmt.Sprintf("%d", nextTestValue+1)
+				}
+			case "uint":
+				if CreateFirstTestValue {
+					spec.Resources[i].Fields[j].TestValues[0] =
+						fmt.Sprintf("%d", nextTestValue)
+				}
+				if CreateSecondTestValue {
+					spec.Resources[i].Fields[j].TestValues[1] =
+						fmt.Sprintf("%d", nextTestValue+1)
+				}
+			case "float":
+				if CreateFirstTestValue {
+					spec.Resources[i].Fields[j].TestValues[0] =
+						fmt.Sprintf("%d.1", nextTestValue)
+				}
+				if CreateSecondTestValue {
+					spec.Resources[i].Fields[j].TestValues[1] =
+						fmt.Sprintf("%d.1", nextTestValue+1)
+				}
+			case "bool":
+				if CreateFirstTestValue {
+					spec.Resources[i].Fields[j].TestValues[0] = "true"
+				}
+				if CreateSecondTestValue {
+					spec.Resources[i].Fields[j].TestValues[1] = "false"
+				}
+			default:
+				// checkSpec reports the unknown type.
+			}
+
+			nextTestValue += 2 // 1, 3, 5 ...
+		}
+	}
+
+	return problems
+}
+
+// lowerFirstRune takes a string and ensures that the first rune is lower case.
+// From https://play.golang.org/p/D8cYDgfZr8 via
+// https://groups.google.com/forum/#!topic/golang-nuts/WfpmVDQFecU
+func lowerFirstRune(s string) string {
+	if s == "" {
+		return ""
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+// upperFirstRune takes a string and ensures that the first rune is upper case.
+// For origins, see lowerFirstRune.
+func upperFirstRune(s string) string {
+	if s == "" {
+		return ""
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[n:]
+}
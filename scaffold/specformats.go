@@ -0,0 +1,370 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// The spec can be written in JSON, YAML or TOML, chosen by the extension of
+// the file: ".yaml" or ".yml" for YAML, ".toml" for TOML and anything else for
+// JSON.  YAML and TOML allow comments, so the reasons for the design can be
+// kept beside the resources and fields.  The keys are the same in every
+// format:
+//
+//	# The animals in the shop.
+//	name: animals
+//	resources:
+//	  - name: mouse
+//	    plural: mice    # not mouses
+//	    fields:
+//	      - {name: name, type: string, mandatory: true}
+//
+// A YAML or TOML spec is converted to JSON and then checked in the same way as
+// a JSON spec, but the problems are reported at their positions in the
+// original file.
+
+// specFileNames are the names of the default spec file, in order of
+// preference.
+var specFileNames = []string{"scaffold.json", "scaffold.yaml", "scaffold.yml", "scaffold.toml"}
+
+// DefaultSpecFile returns the name of the spec file to use when none is
+// given: the first of specFileNames that exists in the current directory, or
+// "scaffold.json" if none of them does.
+func DefaultSpecFile(fsys FS) string {
+	for _, name := range specFileNames {
+		if fileExists(fsys, name) {
+			return name
+		}
+	}
+	return specFileNames[0]
+}
+
+// sourcePosition is the position of an item in a YAML or TOML spec.
+type sourcePosition struct {
+	line   int
+	column int // 0 if not known
+}
+
+// String returns the position in the form "line:column" or "line".
+func (p sourcePosition) String() string {
+	if p.column == 0 {
+		return strconv.Itoa(p.line)
+	}
+	return fmt.Sprintf("%d:%d", p.line, p.column)
+}
+
+// convertSpec converts the text of a YAML or TOML spec to JSON.  It returns
+// the JSON and the position in the original text of each item, keyed by its
+// path in lower case.  A JSON spec is returned unchanged, with no positions.
+// If the text can't be parsed, it returns a problem with its position set.
+func convertSpec(specFile string, data []byte) ([]byte, map[string]sourcePosition, *Problem) {
+	var value interface{}
+	var positions map[string]sourcePosition
+	var problem *Problem
+
+	switch strings.ToLower(filepath.Ext(specFile)) {
+	case ".yaml", ".yml":
+		value, positions, problem = convertYAML(specFile, data)
+	case ".toml":
+		value, positions, problem = convertTOML(specFile, data)
+	default:
+		return data, nil, nil
+	}
+	if problem != nil {
+		return nil, nil, problem
+	}
+
+	result, err := json.MarshalIndent(value, "", "    ")
+	if err != nil {
+		return nil, nil, &Problem{offset: -1, Position: specFile,
+			Message: "cannot convert the spec to JSON - " + err.Error()}
+	}
+	return result, positions, nil
+}
+
+// yamlErrorLine matches the line number in an error from the YAML parser.
+var yamlErrorLine = regexp.MustCompile(`^yaml: line ([0-9]+): `)
+
+// convertYAML parses a YAML spec and returns its value in the form that the
+// JSON encoder expects, plus the position of each item.
+func convertYAML(specFile string, data []byte) (interface{}, map[string]sourcePosition, *Problem) {
+	var document yaml.Node
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		message := err.Error()
+		position := specFile
+		if match := yamlErrorLine.FindStringSubmatch(message); match != nil {
+			position += ":" + match[1]
+			message = message[len(match[0]):]
+		}
+		return nil, nil, &Problem{offset: -1, Position: position,
+			Message: "syntax error - " + strings.TrimPrefix(message, "yaml: ")}
+	}
+
+	positions := make(map[string]sourcePosition)
+	if len(document.Content) == 0 {
+		// An empty file.
+		return map[string]interface{}{}, positions, nil
+	}
+	value, err := yamlValue(document.Content[0], "", reflect.TypeOf(Spec{}), positions)
+	if err != nil {
+		return nil, nil, &Problem{offset: -1, Position: specFile, Message: err.Error()}
+	}
+	return value, positions, nil
+}
+
+// yamlValue converts a YAML node which is to be decoded into a value of the
+// given type.  If the type is nil, it's not known.  A scalar that is to be
+// decoded into a string is kept as it was written, so a test value or a port
+// number can be given without quotes.
+func yamlValue(node *yaml.Node, path string, t reflect.Type,
+	positions map[string]sourcePosition) (interface{}, error) {
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if _, ok := positions[strings.ToLower(path)]; !ok {
+		positions[strings.ToLower(path)] = sourcePosition{node.Line, node.Column}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		result := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			name, valueType, _ := jsonKey(t, key)
+			child := name
+			if path != "" {
+				child = path + "." + name
+			}
+			// Problems with an item are reported at its key.
+			positions[strings.ToLower(child)] = sourcePosition{node.Content[i].Line,
+				node.Content[i].Column}
+			value, err := yamlValue(node.Content[i+1], child, valueType, positions)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+		}
+		return result, nil
+
+	case yaml.SequenceNode:
+		result := make([]interface{}, len(node.Content))
+		for i, element := range node.Content {
+			value, err := yamlValue(element, fmt.Sprintf("%s[%d]", path, i),
+				elementType(t), positions)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = value
+		}
+		return result, nil
+
+	case yaml.ScalarNode:
+		if t != nil && t.Kind() == reflect.String && node.Tag != "!!null" {
+			return node.Value, nil
+		}
+		var value interface{}
+		err := node.Decode(&value)
+		return value, err
+	}
+
+	return nil, fmt.Errorf("%d:%d: cannot use this YAML item in the spec",
+		node.Line, node.Column)
+}
+
+// convertTOML parses a TOML spec and returns its value in the form that the
+// JSON encoder expects, plus the position of each item.
+func convertTOML(specFile string, data []byte) (interface{}, map[string]sourcePosition, *Problem) {
+	var value map[string]interface{}
+	_, err := toml.Decode(string(data), &value)
+	if err != nil {
+		var parseError toml.ParseError
+		if errors.As(err, &parseError) {
+			return nil, nil, &Problem{offset: -1,
+				Position: fmt.Sprintf("%s:%d:%d", specFile, parseError.Position.Line,
+					parseError.Position.Col),
+				Message: "syntax error - " + parseError.Message}
+		}
+		return nil, nil, &Problem{offset: -1, Position: specFile,
+			Message: "syntax error - " + err.Error()}
+	}
+	return tomlValue(value, reflect.TypeOf(Spec{})), tomlPositions(data), nil
+}
+
+// tomlValue converts a value from the TOML decoder which is to be decoded
+// into a value of the given type.  Like yamlValue, it turns a number or a
+// boolean into a string if that's what is wanted.
+func tomlValue(value interface{}, t reflect.Type) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for key, item := range v {
+			_, itemType, _ := jsonKey(t, key)
+			result[key] = tomlValue(item, itemType)
+		}
+		return result
+	case []map[string]interface{}:
+		// An array of tables, such as [[resources]].
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = tomlValue(item, elementType(t))
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = tomlValue(item, elementType(t))
+		}
+		return result
+	}
+	if t != nil && t.Kind() == reflect.String {
+		return fmt.Sprint(value)
+	}
+	return value
+}
+
+// elementType returns the type of the elements of a slice, or nil.
+func elementType(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Slice {
+		return t.Elem()
+	}
+	return nil
+}
+
+// tomlHeader matches a table header such as [inflections] or an array of
+// tables header such as [[resources.fields]].
+var tomlHeader = regexp.MustCompile(`^(\[\[?)\s*([^\]]+?)\s*\]\]?`)
+
+// tomlKey matches the key at the start of a key/value line.
+var tomlKey = regexp.MustCompile(`^([A-Za-z0-9_\-."' ]+?)\s*=`)
+
+// tomlPositions finds the position of the tables and keys in the text of a
+// TOML spec.  The TOML decoder doesn't say where each item is, so this looks
+// at the start of each line for a table header or a key.  That covers the
+// usual layout, with a [[resources]] table for each resource and a
+// [[resources.fields]] table for each field.  Items inside inline tables and
+// arrays are not found, so problems with them are reported at the key that
+// contains them.
+func tomlPositions(data []byte) map[string]sourcePosition {
+	positions := make(map[string]sourcePosition)
+	counts := make(map[string]int) // the number of tables in each array
+	table := ""
+
+	// resolve turns a dotted key into a path, adding the index of the latest
+	// element of each array of tables that it passes through.
+	resolve := func(prefix string, key string) string {
+		path := prefix
+		for _, part := range strings.Split(key, ".") {
+			part = strings.Trim(strings.TrimSpace(part), `"'`)
+			if path != "" {
+				path += "."
+			}
+			path += strings.ToLower(part)
+			if n, ok := counts[path]; ok {
+				path = fmt.Sprintf("%s[%d]", path, n-1)
+			}
+		}
+		return path
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		text := strings.TrimLeft(line, " \t")
+		column := len(line) - len(text) + 1
+		if match := tomlHeader.FindStringSubmatch(text); match != nil {
+			if match[1] == "[[" {
+				// A new element of an array of tables.
+				key := match[2]
+				array := ""
+				if j := strings.LastIndex(key, "."); j >= 0 {
+					array = resolve("", key[:j]) + "."
+					key = key[j+1:]
+				}
+				array += strings.ToLower(strings.Trim(strings.TrimSpace(key), `"'`))
+				counts[array]++
+				table = fmt.Sprintf("%s[%d]", array, counts[array]-1)
+			} else {
+				table = resolve("", match[2])
+			}
+			positions[table] = sourcePosition{i + 1, column}
+			continue
+		}
+		if match := tomlKey.FindStringSubmatch(text); match != nil {
+			path := resolve(table, match[1])
+			if _, ok := positions[path]; !ok {
+				positions[path] = sourcePosition{i + 1, column}
+			}
+		}
+	}
+	return positions
+}
+
+// sourcePositionProblems sets the position of each problem in a YAML or TOML
+// spec and sorts them into the order of their positions.  The problems were
+// found in the JSON produced by convertSpec, so a problem that only has an
+// offset in the JSON is first matched to the item at that offset.
+func sourcePositionProblems(specFile string, jsonPositions map[string]int64,
+	positions map[string]sourcePosition, problems []Problem) []Problem {
+
+	found := make([]sourcePosition, len(problems))
+	for i, problem := range problems {
+		if problem.Position != "" {
+			continue
+		}
+		path := problem.path
+		if path == "" && problem.offset >= 0 {
+			path = jsonPath(jsonPositions, problem.offset)
+		}
+		problems[i].Position = specFile
+		for path := strings.ToLower(path); path != ""; {
+			if position, ok := positions[path]; ok {
+				found[i] = position
+				problems[i].Position = specFile + ":" + position.String()
+				break
+			}
+			j := strings.LastIndexAny(path, ".[")
+			if j < 0 {
+				break
+			}
+			path = path[:j]
+		}
+	}
+
+	order := make([]int, len(problems))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := found[order[i]], found[order[j]]
+		return a.line < b.line || a.line == b.line && a.column < b.column
+	})
+	sorted := make([]Problem, len(problems))
+	for i, k := range order {
+		sorted[i] = problems[k]
+	}
+	return sorted
+}
+
+// jsonPath returns the path of the item in the JSON which starts at or most
+// closely before the offset.
+func jsonPath(jsonPositions map[string]int64, offset int64) string {
+	result := ""
+	best := int64(-1)
+	for path, start := range jsonPositions {
+		if start <= offset && (start > best || start == best && len(path) > len(result)) {
+			result = path
+			best = start
+		}
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package scaffold
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// yamlSpec and tomlSpec are the same small spec in YAML and TOML.
+const yamlSpec = `# The animals in the shop.
+name: animals
+sourcebase: github.com/goblimey/animals
+db: mysql
+dbuser: webuser
+dbpassword: secret
+dbserver: localhost
+orm: gorp
+resources:
+  - name: mouse
+    plural: mice    # not mouses
+    fields:
+      - {name: name, type: string, mandatory: true}
+      - name: age
+        type: int
+        testValues: [3, 4]
+`
+
+const tomlSpec = `# The animals in the shop.
+name = "animals"
+sourcebase = "github.com/goblimey/animals"
+db = "mysql"
+dbuser = "webuser"
+dbpassword = "secret"
+dbserver = "localhost"
+orm = "gorp"
+
+[[resources]]
+name = "mouse"
+plural = "mice"    # not mouses
+
+  [[resources.fields]]
+  name = "name"
+  type = "string"
+  mandatory = true
+
+  [[resources.fields]]
+  name = "age"
+  type = "int"
+  testValues = [3, 4]
+`
+
+func TestLoadYAMLAndTOML(t *testing.T) {
+	want := []Field{
+		{Name: "name", Type: "string", Mandatory: true},
+		{Name: "age", Type: "int", TestValues: []string{"3", "4"}},
+	}
+	for _, specFile := range []string{"scaffold.yaml", "scaffold.yml", "scaffold.toml"} {
+		text := yamlSpec
+		if strings.HasSuffix(specFile, ".toml") {
+			text = tomlSpec
+		}
+		fsys := &MemFS{}
+		fsys.WriteFile(specFile, []byte(text), 0644)
+		spec, problems, err := LoadSpec(fsys, specFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) > 0 {
+			t.Errorf("%s: problems with the spec:\n%s", specFile, problems.Error())
+			continue
+		}
+		if len(spec.Resources) != 1 || spec.Resources[0].PluralName != "mice" {
+			t.Errorf("%s: want the resource mouse with the plural mice, got %v",
+				specFile, spec.Resources)
+			continue
+		}
+		if !reflect.DeepEqual(spec.Resources[0].Fields, want) {
+			t.Errorf("%s: want fields\n%v\ngot\n%v", specFile, want, spec.Resources[0].Fields)
+		}
+	}
+}
+
+func TestProblemPositions(t *testing.T) {
+	var testData = []struct {
+		specFile string
+		text     string
+		want     string
+	}{
+		{"scaffold.yaml", strings.Replace(yamlSpec, "type: int", "type: integr", 1),
+			`scaffold.yaml:15:9: field "age" has type "integr"`},
+		{"scaffold.yaml", strings.Replace(yamlSpec, "orm: gorp", "orm: gorp\nnmae: x", 1),
+			`scaffold.yaml:9:1: unknown key "nmae"`},
+		{"scaffold.yaml", strings.Replace(yamlSpec, "    fields:", "   fields:", 1),
+			`scaffold.yaml:9: syntax error - did not find expected '-' indicator`},
+		{"scaffold.toml", strings.Replace(tomlSpec, `type = "int"`, `type = "integr"`, 1),
+			`scaffold.toml:21:3: field "age" has type "integr"`},
+		{"scaffold.toml", strings.Replace(tomlSpec, `orm = "gorp"`, `orm = "gorp"`+"\nnmae = \"x\"", 1),
+			`scaffold.toml:9:1: unknown key "nmae"`},
+		{"scaffold.toml", strings.Replace(tomlSpec, `name = "mouse"`, `name = "mouse`, 1),
+			`scaffold.toml:11:14: syntax error - strings cannot contain newlines`},
+	}
+
+	for _, td := range testData {
+		fsys := &MemFS{}
+		fsys.WriteFile(td.specFile, []byte(td.text), 0644)
+		_, problems, err := LoadSpec(fsys, td.specFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, problem := range problems {
+			if strings.HasPrefix(problem.String(), td.want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("want %s, got\n%s", td.want, problems.Error())
+		}
+	}
+}
+
+func TestTOMLPositions(t *testing.T) {
+	positions := tomlPositions([]byte(tomlSpec))
+	var testData = []struct {
+		path string
+		want sourcePosition
+	}{
+		{"name", sourcePosition{2, 1}},
+		{"resources[0]", sourcePosition{10, 1}},
+		{"resources[0].plural", sourcePosition{12, 1}},
+		{"resources[0].fields[0]", sourcePosition{14, 3}},
+		{"resources[0].fields[1].type", sourcePosition{21, 3}},
+	}
+	for _, td := range testData {
+		if got, ok := positions[td.path]; !ok || got != td.want {
+			t.Errorf("%s: want %s, got %s", td.path, td.want, got)
+		}
+	}
+}
+
+func TestDefaultSpecFile(t *testing.T) {
+	fsys := &MemFS{}
+	if got := DefaultSpecFile(fsys); got != "scaffold.json" {
+		t.Errorf("no spec: want scaffold.json, got %s", got)
+	}
+	fsys.WriteFile("scaffold.toml", []byte(tomlSpec), 0644)
+	if got := DefaultSpecFile(fsys); got != "scaffold.toml" {
+		t.Errorf("want scaffold.toml, got %s", got)
+	}
+	fsys.WriteFile("scaffold.yaml", []byte(yamlSpec), 0644)
+	if got := DefaultSpecFile(fsys); got != "scaffold.yaml" {
+		t.Errorf("want scaffold.yaml, got %s", got)
+	}
+}
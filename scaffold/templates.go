@@ -0,0 +1,152 @@
+package scaffold
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// builtInTemplates holds the templates and the output list from the templates
+// directory, which are compiled into the scaffolder so that the user doesn't
+// need to specify where to find them.
+//
+//go:embed templates/*.template templates/outputs.json
+var builtInTemplates embed.FS
+
+// ProjectTemplateDir is the directory in the project that holds the user's
+// versions of any templates that should be used instead of the built-in ones.
+const ProjectTemplateDir = "scaffold-templates"
+
+// createTemplateFromFile creates a template from a file.  The file is in the
+// templates directory wherever the scaffolder is installed, and that is out of our
+// control, so this should only be used when the TemplateDir option is
+// specified, or for the user's own templates in the project.
+func (g *generator) createTemplateFromFile(templateName string, templateFile string) (*template.Template, error) {
+	buf, err := g.FS.ReadFile(templateFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open template file %s - %s ",
+			templateFile, err.Error())
+	}
+	t, err := template.New(templateName).Funcs(g.funcs).Parse(string(buf))
+	if err != nil {
+		return nil, fmt.Errorf("template file %s - %s", templateFile, err.Error())
+	}
+	return t, nil
+}
+
+// createTemplates creates a template for each of the built-in templates and
+// for any extra templates in a template pack.  The text of each template is
+// taken from the first of these that exists:
+//
+//	the file of the same name in the project's scaffold-templates directory
+//	the file of the same name in the TemplateDir directory, if specified
+//	the built-in template
+//
+// so the user can replace any subset of the templates.  A template pack can
+// also add templates of its own, which are used by its output list.  Those in
+// the project are recorded in packTemplates, keyed by name, with the name of
+// the file as the value, so that they can be checked against the output list.
+func (g *generator) createTemplates() error {
+	templateNames := BuiltInTemplateNames()
+	known := make(map[string]bool)
+	for _, templateName := range templateNames {
+		known[templateName] = true
+	}
+
+	if g.TemplateDir != "" {
+		_, err := g.FS.Stat(g.TemplateDir)
+		if err != nil {
+			return fmt.Errorf("cannot open template directory %s - %s", g.TemplateDir,
+				err.Error())
+		}
+		templateFiles, _ := glob(g.FS, filepath.Join(g.TemplateDir, "*.template"))
+		for _, templateFile := range templateFiles {
+			templateName := filepath.Base(templateFile)
+			if !known[templateName] {
+				known[templateName] = true
+				templateNames = append(templateNames, templateName)
+			}
+		}
+	}
+
+	// Warn about any files in the project's template directory that can't
+	// be templates - most likely a typing error.  Templates that don't
+	// replace a built-in one are checked against the output list later.
+	// The project doesn't need a template directory, but if there's one that
+	// can't be read, its templates would be silently ignored.
+	overrideDir := filepath.Join(g.ProjectDir, ProjectTemplateDir)
+	overrides, err := g.FS.ReadDir(overrideDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot read the project's template directory %s - %s",
+			overrideDir, err.Error())
+	}
+	for _, entry := range overrides {
+		name := entry.Name()
+		switch {
+		case entry.IsDir() || name == OutputListName:
+		case !strings.HasSuffix(name, ".template"):
+			g.warn("%s/%s is not a template or an output list and is ignored",
+				ProjectTemplateDir, name)
+		case !known[name]:
+			known[name] = true
+			templateNames = append(templateNames, name)
+			g.packTemplates[name] = ProjectTemplateDir + "/" + name
+		}
+	}
+
+	for _, templateName := range templateNames {
+		templateFile := filepath.Join(overrideDir, templateName)
+		if !fileExists(g.FS, templateFile) && g.TemplateDir != "" {
+			templateFile = filepath.Join(g.TemplateDir, templateName)
+		}
+		if fileExists(g.FS, templateFile) {
+			g.logf("creating template %s from file %s", templateName, templateFile)
+			t, err := g.createTemplateFromFile(templateName, templateFile)
+			if err != nil {
+				return err
+			}
+			g.templates[templateName] = t
+			continue
+		}
+
+		g.logf("creating template %s from builtin template", templateName)
+		text, err := BuiltInTemplate(templateName)
+		if err != nil {
+			return err
+		}
+		t, err := template.New(templateName).Funcs(g.funcs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("built-in template %s - %s", templateName, err.Error())
+		}
+		g.templates[templateName] = t
+	}
+	return nil
+}
+
+// BuiltInTemplateNames returns the names of the built-in templates, not
+// including the output list.
+func BuiltInTemplateNames() []string {
+	// The templates are compiled in, so the pattern can't fail.
+	templateFiles, _ := fs.Glob(builtInTemplates, "templates/*.template")
+	templateNames := make([]string, len(templateFiles))
+	for i, templateFile := range templateFiles {
+		templateNames[i] = path.Base(templateFile)
+	}
+	return templateNames
+}
+
+// BuiltInTemplate returns the text of the named built-in template, or of the
+// built-in output list.
+func BuiltInTemplate(templateName string) (string, error) {
+	buf, err := builtInTemplates.ReadFile("templates/" + templateName)
+	if err != nil {
+		return "", fmt.Errorf("cannot read built-in template %s - %s",
+			templateName, err.Error())
+	}
+	return string(buf), nil
+}
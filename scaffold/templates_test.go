@@ -0,0 +1,58 @@
+package scaffold
+
+import (
+	"testing"
+)
+
+func TestProjectTemplateOverride(t *testing.T) {
+	var testData = []struct {
+		description string
+		projectDir  string
+	}{
+		{"current directory", "."},
+		{"project directory", "proj"},
+	}
+
+	for _, td := range testData {
+		fsys := &MemFS{}
+		spec := loadTestSpec(t, fsys, testSpec)
+		override := td.projectDir + "/" + ProjectTemplateDir + "/sql.create.db.template"
+		fsys.WriteFile(override, []byte("-- my own {{.Name}} database\n"), 0644)
+
+		_, err := Generate(spec, Options{FS: fsys, ProjectDir: td.projectDir})
+		if err != nil {
+			t.Fatalf("%s: %v", td.description, err)
+		}
+		content, err := fsys.ReadFile(td.projectDir + "/generated/sql/create.db.sql")
+		if err != nil {
+			t.Fatalf("%s: %v", td.description, err)
+		}
+		if string(content) != "-- my own animals database\n" {
+			t.Errorf("%s: the override was not used - got %q", td.description, content)
+		}
+	}
+}
+
+func TestTemplateDirErrors(t *testing.T) {
+	var testData = []struct {
+		description string
+		setUp       func(fsys *MemFS)
+		options     Options
+	}{
+		{"missing template directory", func(fsys *MemFS) {}, Options{TemplateDir: "nowhere"}},
+		{"template directory is a file", func(fsys *MemFS) {
+			fsys.WriteFile(ProjectTemplateDir, []byte("oops"), 0644)
+		}, Options{}},
+	}
+
+	for _, td := range testData {
+		fsys := &MemFS{}
+		spec := loadTestSpec(t, fsys, testSpec)
+		td.setUp(fsys)
+		td.options.FS = fsys
+		_, err := Generate(spec, td.options)
+		if err == nil {
+			t.Errorf("%s: want an error", td.description)
+		}
+	}
+}
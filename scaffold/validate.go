@@ -0,0 +1,248 @@
+package scaffold
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The names in the spec end up as Go identifiers, package names, SQL table
+// names and SQL column names, so a name that is fine in the JSON can produce
+// code that doesn't compile (a field called "type") or SQL that fails when
+// the server runs (a table called "order").  validateSpec checks the names
+// before anything is generated.  Problems in the Go code are errors, with a
+// suggested alternative.  SQL reserved words are only a warning, because the
+// generated SQL quotes them (see sqlName).
+
+// goIdentifier matches a valid (ASCII) Go identifier.
+var goIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sqlIdentifier matches an unquoted MySQL identifier.
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// goKeywords are the Go keywords, which can't be used as identifiers at all.
+var goKeywords = wordSet(`break case chan const continue default defer else
+	fallthrough for func go goto if import interface map package range return
+	select struct switch type var`)
+
+// goPredeclaredTypes are the predeclared types and constants.  Using one of
+// them as a name hides it, and the generated code uses most of them.
+var goPredeclaredTypes = wordSet(`any bool byte comparable complex64 complex128
+	error float32 float64 int int8 int16 int32 int64 rune string uint uint8
+	uint16 uint32 uint64 uintptr true false iota nil`)
+
+// goBuiltinFunctions are the predeclared functions.  Using one of them as a
+// name hides it, which is only a problem if the generated code calls it in
+// the same scope.
+var goBuiltinFunctions = wordSet(`append cap clear close complex copy delete
+	imag len make max min new panic print println real recover`)
+
+// generatedResourceNames and generatedFieldNames are the names that the
+// generated code uses for itself, so a resource or a field can't have them.
+// The resource packages are imported alongside the metrics, services and
+// utilities packages, and every model has an id field.
+var generatedResourceNames = wordSet(`metrics services utilities`)
+var generatedFieldNames = wordSet(`id`)
+
+// sqlReservedWords are the reserved words of MySQL and Postgres that are
+// likely to be used as a table or column name.
+var sqlReservedWords = wordSet(`accessible add all alter analyze and array as
+	asc asensitive authorization before between bigint binary blob both by call
+	cascade case cast change char character check collate column condition
+	constraint continue convert create cross current_date current_time
+	current_timestamp current_user cursor database databases day_hour
+	day_minute day_second dec decimal declare default delayed delete desc
+	describe deterministic distinct distinctrow div do double drop dual each
+	else elseif enclosed end escaped except exists exit explain false fetch
+	float for force foreign freeze from full fulltext function generated get
+	grant group groups having high_priority hour_minute hour_second if ignore
+	ilike in index infile inner inout insensitive insert int integer intersect
+	interval into is isnull iterate join key keys kill lateral lead leading
+	leave left like limit linear lines load localtime localtimestamp lock long
+	loop low_priority match natural not notnull null numeric offset on only
+	optimize option optionally or order out outer outfile over partition
+	placing precision primary procedure purge range rank read reads real
+	references regexp release rename repeat replace require resignal restrict
+	return returning revoke right rlike row rows schema schemas select
+	sensitive separator session_user set show signal similar smallint some
+	spatial specific sql sqlexception sqlstate sqlwarning ssl starting stored
+	straight_join symmetric system table terminated then to trailing trigger
+	true undo union unique unlock unsigned update usage use user using
+	utc_date utc_time utc_timestamp values varchar varying verbose virtual when
+	where while window with write xor year_month zerofill`)
+
+// wordSet turns a space-separated list of words into a set.
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// validateSpec checks the names in the enhanced spec and returns the
+// problems that it finds.
+func validateSpec(spec Spec) []Problem {
+	problems := make([]Problem, 0)
+	resourceNames := make(map[string]string)
+	tableNames := make(map[string]string)
+	for i, resource := range spec.Resources {
+		path := fmt.Sprintf("resources[%d]", i)
+
+		alternative := CamelCase(resource.Name + " item")
+		problem, isError := checkGoName(resource.Name, resource.NameWithLowerFirst,
+			alternative)
+		if problem == "" && generatedResourceNames[resource.NameAllLower] {
+			problem = fmt.Sprintf("%q is used by the generated code%s",
+				resource.Name, suggestGoName(alternative, "try %q instead"))
+			isError = true
+		}
+		if problem != "" {
+			problems = append(problems, Problem{path: path + ".name",
+				Message: "resource name " + problem, Warning: !isError})
+		}
+
+		problem, isError = checkGoName(resource.PluralName,
+			resource.PluralNameWithLowerFirst, CamelCase(resource.Name+" list"))
+		if problem != "" {
+			problems = append(problems, Problem{path: path + ".plural",
+				Message: "plural " + problem, Warning: !isError})
+		}
+
+		// Names that differ only in case produce the same package name.
+		if other, ok := resourceNames[resource.NameAllLower]; ok {
+			problems = append(problems, Problem{path: path + ".name",
+				Message: fmt.Sprintf("resource %q clashes with resource %q",
+					resource.Name, other)})
+		}
+		resourceNames[resource.NameAllLower] = resource.Name
+
+		switch {
+		case !sqlIdentifier.MatchString(resource.TableName):
+			problems = append(problems, Problem{path: path + ".tableName",
+				Message: fmt.Sprintf("table name %q is not a valid SQL name - try %q instead",
+					resource.TableName, snakeCase(resource.TableName))})
+		case sqlReservedWords[strings.ToLower(resource.TableName)]:
+			problems = append(problems, Problem{path: path + ".tableName",
+				Message: fmt.Sprintf("table name %q is an SQL reserved word, so it will be quoted",
+					resource.TableName),
+				Warning: true})
+		}
+		if other, ok := tableNames[strings.ToLower(resource.TableName)]; ok {
+			problems = append(problems, Problem{path: path + ".tableName",
+				Message: fmt.Sprintf("table name %q is also used by resource %q",
+					resource.TableName, other)})
+		}
+		tableNames[strings.ToLower(resource.TableName)] = resource.Name
+
+		fieldNames := make(map[string]string)
+		// MySQL column names are not case sensitive, and every table has
+		// an id column.
+		columnNames := map[string]string{"id": "id"}
+		for j, field := range resource.Fields {
+			path := fmt.Sprintf("resources[%d].fields[%d].name", i, j)
+			alternative := CamelCase(resource.Name + " " + field.Name)
+
+			problem, isError := checkGoName(field.Name, field.NameWithLowerFirst,
+				alternative)
+			if problem == "" && generatedFieldNames[field.NameAllLower] {
+				problem = fmt.Sprintf("%q is used by the generated code%s",
+					field.Name, suggestGoName(alternative, "try %q instead"))
+				isError = true
+			}
+			if problem != "" {
+				problems = append(problems, Problem{path: path,
+					Message: "field name " + problem, Warning: !isError})
+			}
+
+			other, nameClash := fieldNames[field.NameAllLower]
+			if nameClash {
+				problems = append(problems, Problem{path: path,
+					Message: fmt.Sprintf("field %q clashes with field %q in resource %q",
+						field.Name, other, resource.Name)})
+			}
+			fieldNames[field.NameAllLower] = field.Name
+
+			// A column named after its field is reported at the name.  Any
+			// valid field name is a valid column name.
+			columnPath := path
+			if field.Column != field.NameWithLowerFirst {
+				columnPath = fmt.Sprintf("resources[%d].fields[%d].column", i, j)
+			}
+			switch {
+			case field.Column == "":
+				// checkSpec reports missing names.
+				continue
+			case columnPath != path && !sqlIdentifier.MatchString(field.Column):
+				problems = append(problems, Problem{path: columnPath,
+					Message: fmt.Sprintf("column name %q is not a valid SQL name - try %q instead",
+						field.Column, snakeCase(field.Column))})
+			case sqlReservedWords[strings.ToLower(field.Column)]:
+				problems = append(problems, Problem{path: columnPath,
+					Message: fmt.Sprintf("column name %q is an SQL reserved word, so it will be quoted",
+						field.Column),
+					Warning: true})
+			}
+			// A clash between names has been reported already.
+			if other, ok := columnNames[strings.ToLower(field.Column)]; ok && !nameClash &&
+				!generatedFieldNames[field.NameAllLower] {
+				problems = append(problems, Problem{path: columnPath,
+					Message: fmt.Sprintf("column name %q is also used by field %q in resource %q",
+						field.Column, other, resource.Name)})
+			}
+			columnNames[strings.ToLower(field.Column)] = field.Name
+		}
+	}
+	return problems
+}
+
+// checkGoName checks a name from the spec which is used in the generated Go
+// code in the given form (for example with the first letter lowered).  If
+// there is a problem, it returns a description and whether it's an error,
+// otherwise "".  The alternative is suggested if the name clashes with a Go
+// name, as long as the alternative is a usable name itself.
+func checkGoName(name string, goName string, alternative string) (string, bool) {
+	switch {
+	case name == "":
+		// checkSpec reports missing names.
+		return "", false
+	case !goIdentifier.MatchString(name):
+		suggestion := ""
+		if fixed := CamelCase(name); goIdentifier.MatchString(fixed) {
+			suggestion = fmt.Sprintf(" - try %q instead", fixed)
+		}
+		return fmt.Sprintf("%q is not a valid identifier (use letters, digits and underscores, starting with a letter)%s",
+			name, suggestion), true
+	case goKeywords[goName]:
+		return fmt.Sprintf("%q is a Go keyword%s", name,
+			suggestGoName(alternative, "try %q instead")), true
+	case goPredeclaredTypes[goName]:
+		return fmt.Sprintf("%q is a predeclared Go identifier%s",
+			name, suggestGoName(alternative, "try %q instead")), true
+	case goBuiltinFunctions[goName]:
+		return fmt.Sprintf("%q is the name of a built-in Go function, which it will hide%s",
+			name, suggestGoName(alternative, "consider %q")), false
+	}
+	return "", false
+}
+
+// suggestGoName returns " - " and the advice with the alternative name in it,
+// or "" if the alternative can't be used either.
+func suggestGoName(alternative string, advice string) string {
+	goName := lowerFirstRune(alternative)
+	if !goIdentifier.MatchString(alternative) || goKeywords[goName] ||
+		goPredeclaredTypes[goName] || goBuiltinFunctions[goName] {
+		return ""
+	}
+	return " - " + fmt.Sprintf(advice, alternative)
+}
+
+// sqlName returns a table or column name ready to be used in an SQL
+// statement.  If it's a reserved word, it's quoted.  MySQL is the only
+// database supported, so it uses MySQL quotes.
+func sqlName(name string) string {
+	if sqlReservedWords[strings.ToLower(name)] {
+		return "`" + name + "`"
+	}
+	return name
+}
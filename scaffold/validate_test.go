@@ -0,0 +1,111 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+// specWithResource returns a spec containing one resource with the given
+// name and one field.
+func specWithResource(resourceName string, fieldName string) string {
+	return `{
+    "name": "animals",
+    "sourcebase": "github.com/goblimey/animals",
+    "db": "mysql",
+    "dbuser": "webuser",
+    "orm": "gorp",
+    "resources": [{"name": "` + resourceName + `", "plural": "things",
+        "fields": [{"name": "` + fieldName + `", "type": "string"}]}]
+}`
+}
+
+func TestValidateNames(t *testing.T) {
+	var testData = []struct {
+		resource string
+		field    string
+		want     []string // the messages, in order
+	}{
+		{"cat", "name", nil},
+		{"type", "name", []string{`resource name "type" is a Go keyword - try "typeItem" instead`}},
+		{"cat", "len", []string{
+			`warning: field name "len" is the name of a built-in Go function, which it will hide - consider "catLen"`}},
+		{"cat", "string", []string{
+			`field name "string" is a predeclared Go identifier - try "catString" instead`}},
+		{"cat", "id", []string{`field name "id" is used by the generated code - try "catId" instead`}},
+		{"my-thing", "len", []string{
+			`resource name "my-thing" is not a valid identifier (use letters, digits and underscores, starting with a letter) - try "myThing" instead`,
+			`warning: field name "len" is the name of a built-in Go function, which it will hide - consider "myThingLen"`}},
+		{"cat", "my field", []string{
+			`field name "my field" is not a valid identifier (use letters, digits and underscores, starting with a letter) - try "myField" instead`}},
+	}
+
+	for _, td := range testData {
+		fsys := &MemFS{}
+		fsys.WriteFile("scaffold.json", []byte(specWithResource(td.resource, td.field)), 0644)
+		_, problems, err := LoadSpec(fsys, "scaffold.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0)
+		for _, problem := range problems {
+			got = append(got, problem.Message)
+			if problem.Warning {
+				got[len(got)-1] = "warning: " + problem.Message
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(td.want, "\n") {
+			t.Errorf("%s.%s: want\n%s\ngot\n%s", td.resource, td.field,
+				strings.Join(td.want, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestSuggestGoName(t *testing.T) {
+	var testData = []struct {
+		alternative string
+		want        string
+	}{
+		{"catLen", ` - try "catLen" instead`},
+		{"my-thingLen", ""},
+		{"", ""},
+		{"string", ""},
+		{"Type", ""},
+		{"len", ""},
+	}
+
+	for _, td := range testData {
+		if got := suggestGoName(td.alternative, "try %q instead"); got != td.want {
+			t.Errorf("suggestGoName(%q) - want %q, got %q", td.alternative, td.want, got)
+		}
+	}
+}
+
+func TestValidateColumns(t *testing.T) {
+	var testData = []struct {
+		description string
+		fields      string
+		want        []string
+	}{
+		{"column", `{"name": "firstName", "type": "string", "column": "first_name"}`, nil},
+		{"not valid", `{"name": "firstName", "type": "string", "column": "first name"}`, []string{
+			`scaffold.json:10:57: column name "first name" is not a valid SQL name - try "first_name" instead`}},
+		{"reserved", `{"name": "orderedAt", "type": "string", "column": "order"}`, []string{
+			`scaffold.json:10:57: warning: column name "order" is an SQL reserved word, so it will be quoted`}},
+		{"reserved name", `{"name": "order", "type": "string"}`, []string{
+			`scaffold.json:10:18: warning: column name "order" is an SQL reserved word, so it will be quoted`}},
+		{"clash", `{"name": "firstName", "type": "string"}, {"name": "forename", "type": "string", "column": "FirstName"}`, []string{
+			`scaffold.json:10:97: column name "FirstName" is also used by field "firstName" in resource "cat"`}},
+		{"id", `{"name": "ref", "type": "string", "column": "ID"}`, []string{
+			`scaffold.json:10:51: column name "ID" is also used by field "id" in resource "cat"`}},
+		{"field clash", `{"name": "name", "type": "string"}, {"name": "Name", "type": "string"}`, []string{
+			`scaffold.json:10:54: field "Name" clashes with field "name" in resource "cat"`}},
+	}
+
+	for _, td := range testData {
+		problems := checkProblems(t, checkSpecWith(td.fields))
+		if strings.Join(problems, "\n") != strings.Join(td.want, "\n") {
+			t.Errorf("%s: want\n%s\ngot\n%s", td.description,
+				strings.Join(td.want, "\n"), strings.Join(problems, "\n"))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+)
+
+// specSchema is a JSON Schema that describes the spec file.  An editor that
+// understands JSON Schema (VS Code, IntelliJ and many others) can use it to
+// complete and check a spec as the user writes it.  The spec refers to the
+// schema with a "$schema" setting:
+//
+//	"$schema": "https://raw.githubusercontent.com/goblimey/scaffolder/master/scaffold.schema.json",
+//
+// The schema only covers what can be said in JSON Schema.  The scaffolder's own
+// checks (see scaffold.LoadSpec) go further, for example looking for names that clash
+// with Go keywords.  When the Spec, Resource or Field types change, or the
+// checks in checkSpec change, the schema should be changed to match.  The
+// tests in scaffold/schema_test.go compare it with the types.
+//
+//go:embed scaffold.schema.json
+var specSchema string
+
+// schemaCommand runs the "schema" command:
+//
+//	scaffolder schema
+//
+// It writes the JSON Schema for the spec file to the standard output, so that
+// the user can save a copy for an editor that can't fetch it.
+func schemaCommand(args []string) {
+	log.SetPrefix("schema ")
+
+	if len(args) > 0 {
+		log.Println("usage: scaffolder schema")
+		os.Exit(2)
+	}
+	fmt.Print(specSchema)
+}
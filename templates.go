@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/goblimey/scaffolder/scaffold"
+)
+
+// templatesCommand runs the "templates" command:
+//
+//	scaffolder templates list
+//	scaffolder templates export <template name> ...
+//
+// list shows the built-in templates and the output list, marking any that are
+// replaced by a file in the project's scaffold-templates directory.  export
+// copies the named built-in templates (or outputs.json) into that directory as
+// a starting point for the user's own versions.  An existing file is only
+// replaced in overwrite mode.
+func templatesCommand(args []string) {
+	log.SetPrefix("templates ")
+
+	const usage = "usage: scaffolder [-projectdir dir] [-overwrite] templates list|export <template name> ..."
+	if len(args) == 0 {
+		log.Println(usage)
+		os.Exit(-1)
+	}
+
+	overrideDir := filepath.Join(projectDir, scaffold.ProjectTemplateDir)
+
+	switch args[0] {
+	case "list":
+		for _, templateName := range append(scaffold.BuiltInTemplateNames(), scaffold.OutputListName) {
+			if fileExists(filepath.Join(overrideDir, templateName)) {
+				fmt.Printf("%s (replaced by %s/%s)\n", templateName,
+					scaffold.ProjectTemplateDir, templateName)
+			} else {
+				fmt.Println(templateName)
+			}
+		}
+
+	case "export":
+		if len(args) < 2 {
+			log.Println(usage)
+			os.Exit(-1)
+		}
+		known := map[string]bool{scaffold.OutputListName: true}
+		for _, templateName := range scaffold.BuiltInTemplateNames() {
+			known[templateName] = true
+		}
+		for _, templateName := range args[1:] {
+			if !known[templateName] {
+				log.Printf("there is no built-in template called %s - use \"scaffolder templates list\" to see them",
+					templateName)
+				os.Exit(-1)
+			}
+		}
+		err := os.MkdirAll(overrideDir, 0777)
+		if err != nil {
+			log.Printf("cannot create directory %s - %s", overrideDir, err.Error())
+			os.Exit(-1)
+		}
+		for _, templateName := range args[1:] {
+			templateFile := filepath.Join(overrideDir, templateName)
+			if fileExists(templateFile) && !overwriteMode {
+				log.Printf("%s already exists - use -overwrite to replace it",
+					templateFile)
+				continue
+			}
+			text, err := scaffold.BuiltInTemplate(templateName)
+			if err != nil {
+				log.Println(err.Error())
+				os.Exit(-1)
+			}
+			err = ioutil.WriteFile(templateFile, []byte(text), 0644)
+			if err != nil {
+				log.Printf("cannot write %s - %s", templateFile, err.Error())
+				os.Exit(-1)
+			}
+			fmt.Printf("exported %s to %s\n", templateName, templateFile)
+		}
+
+	default:
+		log.Println(usage)
+		os.Exit(-1)
+	}
+}
+
+// fileExists returns true if the named file exists and is not a directory.
+func fileExists(name string) bool {
+	fileInfo, err := os.Stat(name)
+	return err == nil && !fileInfo.IsDir()
+}